@@ -0,0 +1,62 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// addressComponent — часть почтового адреса, которую распознает
+// detectAddress, и ее вклад в уверенность.
+type addressComponent struct {
+	name   string
+	weight float64
+	re     *regexp.Regexp
+}
+
+// addressWord оборачивает выражение границами слова: \b в regexp
+// учитывает только латиницу.
+func addressWord(expr string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?:` + expr + `)(?:[^\p{L}\p{N}]|$)`)
+}
+
+// addressStreetTypes — типы улиц и их сокращения.
+const addressStreetTypes = `ул|улица|пр-т|пр-кт|просп|проспект|пер|переулок|ш|шоссе|б-р|бул|бульвар|наб|набережная|пл|площадь|проезд|пр-д|туп|тупик|аллея|линия|мкр|микрорайон|кв-л|квартал`
+
+var addressComponents = []addressComponent{
+	{"индекс", 0.2, regexp.MustCompile(`(?:^|\D)[1-6]\d{5}(?:\D|$)`)},
+	{"регион", 0.15, addressWord(`обл|область|край|респ|республика|автономный округ|р-н|район`)},
+	{"город", 0.2, addressWord(`(?:г|гор|пос|п|пгт|рп|с|дер|д|ст-ца)\.\s*\p{L}+|город|поселок|село|деревня|станица|москва|санкт-петербург|спб|севастополь`)},
+	{"улица", 0.35, addressWord(addressStreetTypes)},
+	{"дом", 0.25, regexp.MustCompile(`(?:(?:^|[^\p{L}\p{N}])(?:д|дом|вл|влад|владение)\.?\s*|(?:` + addressStreetTypes + `)\.?\s+[^,\d]+[,\s]\s*)\d+\s*\p{L}?(?:/\d+)?(?:[^\p{L}\p{N}]|$)`)},
+	{"корпус", 0.05, regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?:к|корп|корпус|стр|строение|лит|литера)\.?\s*\d+`)},
+	{"квартира", 0.15, regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?:кв|квартира|оф|офис|пом|помещение|комн|комната)\.?\s*\d+`)},
+}
+
+// detectAddress распознает российский почтовый адрес: индекс, регион,
+// населенный пункт, тип улицы, дом, корпус или строение, квартиру или
+// офис. Вес значения — сумма вкладов найденных частей (не больше 1), вид —
+// список частей. Значение засчитывается, если найдены хотя бы две части и
+// среди них улица, населенный пункт или индекс: одиночное «кв. 5» или
+// слово «дом» в тексте адресом не считаются.
+func detectAddress(value string) (float64, string) {
+	value = strings.ToLower(value)
+
+	var found []string
+	weight := 0.0
+	anchored := false
+	for _, c := range addressComponents {
+		if !c.re.MatchString(value) {
+			continue
+		}
+		found = append(found, c.name)
+		weight += c.weight
+		if c.name == "улица" || c.name == "город" || c.name == "индекс" {
+			anchored = true
+		}
+	}
+
+	if len(found) < 2 || !anchored {
+		return 0, ""
+	}
+	return min(1, weight), strings.Join(found, ", ")
+}
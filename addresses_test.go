@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestDetectAddress(t *testing.T) {
+	tests := []struct {
+		value  string
+		weight float64
+		kind   string
+	}{
+		{"г. Москва, ул. Ленина, д. 5 к. 2, кв. 10", 1, "город, улица, дом, корпус, квартира"},
+		{"пр-т Мира, 15", 0.6, "улица, дом"},
+		{"Москва, пр-т Мира, д. 15", 0.8, "город, улица, дом"},
+		{"123456, г. Казань, ул. Баумана, 7", 1, "индекс, город, улица, дом"},
+		{"Московская обл., г. Одинцово", 0.35, "регион, город"},
+		// Одной части адреса недостаточно
+		{"123456", 0, ""},
+		{"ул. Ленина", 0, ""},
+		{"Ленина", 0, ""},
+		{"кв. 5", 0, ""},
+		// Без улицы, населенного пункта или индекса — не адрес
+		{"дом 5 кв 10", 0, ""},
+		{"оплата за кв. 5 и свет", 0, ""},
+		// Слова, начинающиеся с сокращений адреса
+		{"домен", 0, ""},
+		{"домен example.ru", 0, ""},
+		{"Домодедово", 0, ""},
+		{"доставка до дома", 0, ""},
+		{"заказ 123456 от клиента", 0, ""},
+	}
+	for _, tt := range tests {
+		weight, kind := detectAddress(tt.value)
+		if weight != tt.weight || kind != tt.kind {
+			t.Errorf("%q: %v %q, ожидалось %v %q", tt.value, weight, kind, tt.weight, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Способы аутентификации на SQL Server (--auth)
+const (
+	authSQL                 = "sql"
+	authIntegrated          = "integrated"
+	authKerberos            = "kerberos"
+	authAADPassword         = "aad-password"
+	authAADServicePrincipal = "aad-sp"
+)
+
+var authModes = []string{authSQL, authIntegrated, authKerberos, authAADPassword, authAADServicePrincipal}
+
+// AuthOptions — параметры аутентификации, дополняющие логин и пароль.
+type AuthOptions struct {
+	Mode string
+
+	Krb5Config string
+	Krb5Realm  string
+	Keytab     string
+	Krb5Cache  string
+
+	AADClientID string
+	AADTenantID string
+	AADCert     string
+}
+
+func (a *AuthOptions) validate() error {
+	switch a.Mode {
+	case authSQL, authIntegrated:
+	case authKerberos:
+		if a.Keytab != "" && a.Krb5Cache != "" {
+			return errors.New("для kerberos укажите либо keytab, либо кэш билетов, но не оба")
+		}
+	case authAADPassword, authAADServicePrincipal:
+		if a.AADClientID == "" {
+			return fmt.Errorf("для %s требуется идентификатор приложения (--aad-client-id)", a.Mode)
+		}
+	default:
+		return fmt.Errorf("неизвестный способ аутентификации %q, доступны: %s", a.Mode, strings.Join(authModes, ", "))
+	}
+	return nil
+}
+
+// needsUser сообщает, требуется ли логин для выбранного способа входа.
+func (o *Options) needsUser() bool {
+	if o.isLocal() {
+		return false
+	}
+	switch o.Auth.Mode {
+	case authIntegrated:
+		// На Windows используется SSPI текущего пользователя, иначе NTLM с DOMAIN\user
+		return runtime.GOOS != "windows"
+	case authKerberos:
+		return o.Auth.Keytab != ""
+	case authAADServicePrincipal:
+		return false
+	}
+	return true
+}
+
+// needsPassword сообщает, требуется ли пароль (или секрет приложения).
+func (o *Options) needsPassword() bool {
+	if o.isLocal() {
+		return false
+	}
+	switch o.Auth.Mode {
+	case authIntegrated:
+		return runtime.GOOS != "windows" || o.User != ""
+	case authKerberos:
+		return o.Auth.Keytab == "" && o.Auth.Krb5Cache == ""
+	case authAADServicePrincipal:
+		return o.Auth.AADCert == ""
+	}
+	return true
+}
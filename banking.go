@@ -0,0 +1,272 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Контрольный ключ расчетного счета (9-я цифра) рассчитывается по БИК
+// банка, поэтому по одному номеру проверяется только структура счета, а
+// ключ — если в другой колонке той же таблицы есть БИК (см.
+// applyAccountKeys).
+const (
+	// accountKeyFactor — множитель уверенности, если ключ счетов сходится
+	// с БИК из таблицы
+	accountKeyFactor = 2.0
+	// bikMinShare — доля значений колонки, похожих на БИК, начиная с
+	// которой колонка считается колонкой БИК
+	bikMinShare = 0.5
+)
+
+// accountCurrencies — коды валют (ОКВ) в 6–8 разрядах счета: рубль (810 и
+// 643) и распространенные иностранные валюты.
+var accountCurrencies = []string{
+	"810", "643", "840", "978", "156", "826", "756", "392", "398", "933",
+	"980", "949", "344", "356", "203", "985", "752", "578", "208", "124", "036",
+}
+
+// validAccount проверяет структуру номера счета в банке России: 20 цифр,
+// балансовый счет не начинается с 0, код валюты из accountCurrencies.
+func validAccount(s string) error {
+	d := digitsOf(s)
+	if len(d) != 20 {
+		return errors.New("в номере счета не 20 цифр")
+	}
+	if allSame(d) {
+		return errors.New("номер счета из одинаковых цифр")
+	}
+	if d[0] == '0' {
+		return errors.New("номер балансового счета начинается с 0")
+	}
+	if !contains(accountCurrencies, d[5:8]) {
+		return fmt.Errorf("неизвестный код валюты счета %s", d[5:8])
+	}
+	return nil
+}
+
+// validBIK проверяет БИК банка в России: 9 цифр, начинается с 04.
+func validBIK(s string) error {
+	d := digitsOf(s)
+	if len(d) != 9 || !strings.HasPrefix(d, "04") {
+		return errors.New("БИК — 9 цифр, начинается с 04")
+	}
+	return nil
+}
+
+// accountKeyValid проверяет контрольный ключ счета по БИК банка по
+// алгоритму Банка России: к номеру счета слева приписываются три последние
+// цифры БИК (для подразделений Банка России, БИК которых оканчивается на
+// 000, 001 или 002, — 0 и 5–6 цифры БИК), цифры умножаются на веса
+// 7, 1, 3, 7, 1, 3…, и сумма младших разрядов произведений должна делиться
+// на 10.
+func accountKeyValid(account, bik string) bool {
+	prefix := bik[6:]
+	if prefix == "000" || prefix == "001" || prefix == "002" {
+		prefix = "0" + bik[4:6]
+	}
+	weights := []int{7, 1, 3}
+	sum := 0
+	for i, c := range prefix + account {
+		sum += int(c-'0') * weights[i%3] % 10
+	}
+	return sum%10 == 0
+}
+
+// tableBIKs возвращает БИК из колонок таблицы, в которых не меньше
+// bikMinShare значений выборки — БИК, и имена этих колонок.
+func tableBIKs(results []PDNResult) ([]string, []string) {
+	var biks, columns []string
+	seen := make(map[string]bool)
+	for _, res := range results {
+		if seen[res.ColumnName] || len(res.values) == 0 {
+			continue
+		}
+		seen[res.ColumnName] = true
+
+		var found []string
+		for _, value := range res.values {
+			value = strings.TrimSpace(value)
+			if digitsOf(value) == value && validBIK(value) == nil {
+				found = append(found, value)
+			}
+		}
+		if len(found) > 0 && float64(len(found)) >= bikMinShare*float64(len(res.values)) {
+			biks = append(biks, found...)
+			columns = append(columns, res.ColumnName)
+		}
+	}
+	return biks, columns
+}
+
+// applyAccountKeys проверяет контрольные ключи счетов (правила с validator
+// account) по БИК из других колонок таблицы. Строки выборки разных колонок
+// не сопоставляются, поэтому ключ счета сверяется с каждым БИК таблицы.
+// Если ключи сходятся хотя бы у половины счетов, уверенность умножается на
+// accountKeyFactor, иначе снижается на rejectedPenalty; вердикт
+// пересчитывается. Без колонки БИК результаты не меняются.
+func applyAccountKeys(rules *RuleSet, results []PDNResult) {
+	biks, columns := tableBIKs(results)
+	if len(biks) == 0 {
+		return
+	}
+
+	for i := range results {
+		res := &results[i]
+		if res.rule == nil || res.rule.Validator != "account" || res.Confidence == 0 {
+			continue
+		}
+
+		checked, valid := 0, 0
+		for _, value := range res.values {
+			for _, account := range res.rule.accounts(value) {
+				checked++
+				for _, bik := range biks {
+					if accountKeyValid(account, bik) {
+						valid++
+						break
+					}
+				}
+			}
+		}
+		if checked == 0 {
+			continue
+		}
+
+		factor := accountKeyFactor
+		res.Validation = fmt.Sprintf("ключ счета сходится с БИК: %d из %d", valid, checked)
+		if valid*2 < checked {
+			factor = rejectedPenalty
+			res.Validation = fmt.Sprintf("ключ счета не сходится с БИК таблицы: %d из %d", checked-valid, checked)
+		}
+		res.Confidence = math.Round(min(1, res.Confidence*factor)*100) / 100
+		res.Explanation += fmt.Sprintf("; ключ счета сходится с БИК (колонка %s) у %d из %d (×%.2f)",
+			strings.Join(columns, ", "), valid, checked, factor)
+		res.Verdict = rules.thresholds(res.rule).verdict(res.Confidence)
+	}
+}
+
+// accounts возвращает номера счетов в значении, найденные выражениями
+// правила и прошедшие validAccount.
+func (r *Rule) accounts(value string) []string {
+	var found []string
+	for _, re := range r.patterns {
+		for _, fragment := range re.FindAllString(strings.ToLower(value), -1) {
+			if validAccount(fragment) == nil {
+				found = append(found, digitsOf(fragment))
+			}
+		}
+	}
+	return found
+}
+
+// countryCodes — коды стран ISO 3166-1 для IBAN и SWIFT/BIC, включая XK
+// (Косово), который используется в SWIFT.
+var countryCodes = strings.Fields(`
+	ad ae af ag ai al am ao aq ar as at au aw ax az ba bb bd be bf bg bh bi
+	bj bl bm bn bo bq br bs bt bv bw by bz ca cc cd cf cg ch ci ck cl cm cn
+	co cr cu cv cw cx cy cz de dj dk dm do dz ec ee eg eh er es et fi fj fk
+	fm fo fr ga gb gd ge gf gg gh gi gl gm gn gp gq gr gs gt gu gw gy hk hm
+	hn hr ht hu id ie il im in io iq ir is it je jm jo jp ke kg kh ki km kn
+	kp kr kw ky kz la lb lc li lk lr ls lt lu lv ly ma mc md me mf mg mh mk
+	ml mm mn mo mp mq mr ms mt mu mv mw mx my mz na nc ne nf ng ni nl no np
+	nr nu nz om pa pe pf pg ph pk pl pm pn pr ps pt pw py qa re ro rs ru rw
+	sa sb sc sd se sg sh si sj sk sl sm sn so sr ss st sv sx sy sz tc td tf
+	tg th tj tk tl tm tn to tr tt tv tw tz ua ug um us uy uz va vc ve vg vi
+	vn vu wf ws xk ye yt za zm zw
+`)
+
+// validIBAN проверяет IBAN: код страны, длину от 15 до 34 знаков и
+// контрольные цифры — остаток от деления на 97 номера, в котором первые
+// четыре знака перенесены в конец, а буквы заменены числами (A = 10…),
+// равен 1.
+func validIBAN(s string) error {
+	iban := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	if len(iban) < 15 || len(iban) > 34 {
+		return errors.New("в IBAN должно быть от 15 до 34 знаков")
+	}
+	if !contains(countryCodes, strings.ToLower(iban[:2])) {
+		return fmt.Errorf("неизвестный код страны IBAN %s", iban[:2])
+	}
+
+	rem := 0
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A'+10)) % 97
+		default:
+			return errors.New("недопустимый знак в IBAN")
+		}
+	}
+	if rem != 1 {
+		return errors.New("неверные контрольные цифры IBAN (mod 97)")
+	}
+	return nil
+}
+
+// validSWIFT проверяет код SWIFT/BIC: 4 буквы кода банка, код страны,
+// 2 знака кода местонахождения и необязательные 3 знака кода филиала.
+func validSWIFT(s string) error {
+	code := strings.ToLower(strings.TrimSpace(s))
+	if len(code) != 8 && len(code) != 11 {
+		return errors.New("в коде SWIFT должно быть 8 или 11 знаков")
+	}
+	for i, c := range code {
+		letter := c >= 'a' && c <= 'z'
+		if !letter && (i < 6 || c < '0' || c > '9') {
+			return errors.New("код SWIFT не в формате AAAABBCC[DDD]")
+		}
+	}
+	if !contains(countryCodes, code[4:6]) {
+		return fmt.Errorf("неизвестный код страны SWIFT %s", strings.ToUpper(code[4:6]))
+	}
+	return nil
+}
+
+// cardRanges — диапазоны БИН (первых четырех цифр номера карты) платежных
+// систем и допустимая длина номеров.
+var cardRanges = []struct {
+	scheme         string
+	from, to       int
+	minLen, maxLen int
+}{
+	{"Мир", 2200, 2204, 16, 19},
+	{"Mastercard", 2221, 2720, 16, 16},
+	{"Diners Club", 3000, 3059, 14, 19},
+	{"American Express", 3400, 3499, 15, 15},
+	{"JCB", 3528, 3589, 16, 19},
+	{"Diners Club", 3600, 3699, 14, 19},
+	{"American Express", 3700, 3799, 15, 15},
+	{"Diners Club", 3800, 3999, 16, 19},
+	{"Visa", 4000, 4999, 13, 19},
+	{"Maestro", 5000, 5099, 12, 19},
+	{"Mastercard", 5100, 5599, 16, 16},
+	{"Maestro, Discover, UnionPay", 5600, 6999, 12, 19},
+}
+
+// validCard проверяет номер банковской карты по алгоритму Луна и по
+// диапазонам БИН платежных систем (cardRanges).
+func validCard(s string) error {
+	if err := validLuhn(s); err != nil {
+		return err
+	}
+
+	d := digitsOf(s)
+	bin := 0
+	for _, c := range d[:4] {
+		bin = bin*10 + int(c-'0')
+	}
+	for _, r := range cardRanges {
+		if bin < r.from || bin > r.to {
+			continue
+		}
+		if len(d) < r.minLen || len(d) > r.maxLen {
+			return fmt.Errorf("длина номера %s — от %d до %d цифр", r.scheme, r.minLen, r.maxLen)
+		}
+		return nil
+	}
+	return fmt.Errorf("БИН %s не относится ни к одной платежной системе", d[:4])
+}
@@ -0,0 +1,119 @@
+package main
+
+import "testing"
+
+func TestValidIBAN(t *testing.T) {
+	testValidator(t, validIBAN, []validatorCase{
+		{"GB82WEST12345698765432", true},
+		{"DE89370400440532013000", true},
+		{"DE89 3704 0044 0532 0130 00", true},
+		{"gb82west12345698765432", true},
+		{"GB82WEST12345698765433", false},
+		{"DE89370400440532013001", false},
+		{"QQ82WEST12345698765432", false},
+		{"GB82WEST123", false},
+	})
+}
+
+func TestValidSWIFT(t *testing.T) {
+	testValidator(t, validSWIFT, []validatorCase{
+		{"SABRRUMM", true},
+		{"DEUTDEFF500", true},
+		{"sabrrumm", true},
+		{"SABRQQMM", false},
+		{"SABR12MM", false},
+		{"SABRRUM", false},
+		{"DEUTDEFF50", false},
+	})
+}
+
+func TestValidCard(t *testing.T) {
+	testValidator(t, validCard, []validatorCase{
+		{"4111111111111111", true},
+		{"5555555555554444", true},
+		{"378282246310005", true},
+		{"2200000000000004", true},
+		{"4111111111111112", false},
+		{"3782822463100051", false},
+		{"9111111111111110", false},
+	})
+}
+
+func TestAccountKeyValid(t *testing.T) {
+	tests := []struct {
+		account, bik string
+		valid        bool
+	}{
+		{"40702810038000017240", "044525225", true},
+		{"40702810138000017240", "044525225", false},
+		{"40702810038000017240", "044525226", false},
+		// Подразделение Банка России: вместо трех последних цифр БИК
+		// к счету приписываются 0 и 5–6 цифры БИК
+		{"30101810400000000225", "044525000", true},
+		{"30101810500000000225", "044525000", false},
+	}
+	for _, tt := range tests {
+		if got := accountKeyValid(tt.account, tt.bik); got != tt.valid {
+			t.Errorf("accountKeyValid(%s, %s) = %v, ожидалось %v", tt.account, tt.bik, got, tt.valid)
+		}
+	}
+}
+
+func TestApplyAccountKeys(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accountRule *Rule
+	for _, r := range rules.Rules {
+		if r.Validator == "account" {
+			accountRule = r
+		}
+	}
+	if accountRule == nil {
+		t.Fatal("нет правила с validator account")
+	}
+
+	table := func(bik string, accounts ...string) []PDNResult {
+		return []PDNResult{
+			{ColumnName: "bik", values: []string{bik, bik}},
+			{ColumnName: "account", rule: accountRule, Confidence: 0.4, values: accounts},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		results    []PDNResult
+		confidence float64
+		validation string
+	}{
+		{
+			name:       "ключ сходится",
+			results:    table("044525225", "40702810038000017240", "40702810038000017240"),
+			confidence: 0.8,
+			validation: "ключ счета сходится с БИК: 2 из 2",
+		},
+		{
+			name:       "ключ не сходится",
+			results:    table("044525225", "40702810138000017240", "40702810138000017240"),
+			confidence: 0.2,
+			validation: "ключ счета не сходится с БИК таблицы: 2 из 2",
+		},
+		{
+			name:       "нет колонки БИК",
+			results:    table("не БИК", "40702810138000017240"),
+			confidence: 0.4,
+		},
+	}
+	for _, tt := range tests {
+		applyAccountKeys(rules, tt.results)
+		res := tt.results[1]
+		if res.Confidence != tt.confidence || res.Validation != tt.validation {
+			t.Errorf("%s: уверенность %.2f, проверка %q; ожидалось %.2f, %q",
+				tt.name, res.Confidence, res.Validation, tt.confidence, tt.validation)
+		}
+		if want := rules.thresholds(accountRule).verdict(tt.confidence); tt.validation != "" && res.Verdict != want {
+			t.Errorf("%s: вердикт %q, ожидался %q", tt.name, res.Verdict, want)
+		}
+	}
+}
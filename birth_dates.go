@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Возраст, в пределах которого дата считается похожей на дату рождения.
+const (
+	birthMinAge = 14
+	birthMaxAge = 100
+	// birthDateWeight — вес даты в этом диапазоне: по одной дате нельзя
+	// отличить дату рождения от даты договора, поэтому без других ПДн в
+	// таблице (context правила) тип ПДн только возможен
+	birthDateWeight = 0.5
+)
+
+// dateLayouts — форматы дат в выборках: ISO и его варианты из СУБД,
+// русский формат с точками и формат SQL Server по умолчанию для datetime.
+var dateLayouts = []string{
+	time.DateOnly,
+	time.DateTime,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05-07:00",
+	"2006/01/02",
+	"02.01.2006",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"2.1.2006",
+	"02/01/2006",
+	"02-01-2006",
+	"Jan 2 2006 3:04PM",
+}
+
+// defaultDates — даты, которые СУБД и приложения подставляют вместо
+// неизвестной: они не считаются датами рождения.
+var defaultDates = []string{"1900-01-01", "1899-12-30", "1753-01-01", "1970-01-01", "0001-01-01"}
+
+// parseDate разбирает дату в одном из dateLayouts.
+func parseDate(value string) (time.Time, bool) {
+	value = strings.Join(strings.Fields(value), " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// detectBirthDate засчитывает дату без времени суток, соответствующую
+// возрасту от birthMinAge до birthMaxAge лет. Сегодняшние даты, даты со
+// временем (отметки создания записей) и даты-заглушки (defaultDates) не
+// засчитываются.
+func detectBirthDate(value string) (float64, string) {
+	t, ok := parseDate(value)
+	if !ok {
+		return 0, ""
+	}
+	if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+		return 0, ""
+	}
+	if contains(defaultDates, t.Format(time.DateOnly)) {
+		return 0, ""
+	}
+
+	now := time.Now()
+	if t.After(now.AddDate(-birthMinAge, 0, 0)) || t.Before(now.AddDate(-birthMaxAge, 0, 0)) {
+		return 0, ""
+	}
+	return birthDateWeight, fmt.Sprintf("возраст от %d до %d лет", birthMinAge, birthMaxAge)
+}
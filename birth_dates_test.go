@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(1985, 3, 5, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"1985-03-05",
+		"1985-03-05 00:00:00",
+		"1985-03-05T00:00:00Z",
+		"1985-03-05T00:00:00",
+		"1985/03/05",
+		"05.03.1985",
+		"5.3.1985",
+		"05.03.1985 00:00",
+		"05/03/1985",
+		"05-03-1985",
+		"Mar  5 1985 12:00AM",
+	} {
+		got, ok := parseDate(value)
+		if !ok || !got.Equal(want) {
+			t.Errorf("%q: %v %v, ожидалось %v", value, got, ok, want)
+		}
+	}
+
+	for _, value := range []string{"", "1985", "05.03.85", "2024-13-01", "31.02.1985", "вчера"} {
+		if got, ok := parseDate(value); ok {
+			t.Errorf("%q: разобрано как %v", value, got)
+		}
+	}
+}
+
+func TestDetectBirthDate(t *testing.T) {
+	// Границы возраста считаются от сегодняшней даты
+	today := time.Now()
+	date := func(years, days int) string {
+		return today.AddDate(-years, 0, days).Format(time.DateOnly)
+	}
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"1985-03-15", true},
+		{"15.03.1985", true},
+		{"1985-03-15 00:00:00", true},
+		{"1985-03-15T00:00:00Z", true},
+		{"Mar 15 1985 12:00AM", true},
+		// Время суток — отметка события, а не дата рождения
+		{"1985-03-15 10:30:00", false},
+		{"Mar 15 1985 3:04PM", false},
+		{"15.03.1985 09:15", false},
+		// Возраст от 14 до 100 лет
+		{date(birthMinAge, -1), true},
+		{date(birthMinAge, 1), false},
+		{date(birthMaxAge, 1), true},
+		{date(birthMaxAge, -1), false},
+		{date(0, 0), false},
+		{date(-1, 0), false},
+		// Заглушки вместо неизвестной даты
+		{"1970-01-01", false},
+		{"01.01.1970", false},
+		{"1900-01-01", false},
+		{"1753-01-01", false},
+		{"0001-01-01", false},
+		{"не указана", false},
+	}
+	for _, tt := range tests {
+		weight, kind := detectBirthDate(tt.value)
+		if got := weight > 0; got != tt.want {
+			t.Errorf("%q: %v (%q), ожидалось %v", tt.value, weight, kind, tt.want)
+		}
+		if tt.want && weight != birthDateWeight {
+			t.Errorf("%q: вес %v, ожидалось %v", tt.value, weight, birthDateWeight)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config — файл конфигурации с набором профилей сканирования. Формат YAML;
+// JSON также принимается, так как является подмножеством YAML.
+//
+//	defaults:
+//	  sampling: {size: 10}
+//	profiles:
+//	  prod-hr:
+//	    connection: {server: srv1mssql12, database: HR, user: auditor, credentials_file: /etc/pdn/hr.cred}
+//	    exclude: {schemas: [tmp], tables: ["*_log"]}
+type Config struct {
+	Defaults Profile            `yaml:"defaults"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+type Profile struct {
+	Connection ConnectionConfig `yaml:"connection"`
+	Pool       PoolConfig       `yaml:"pool"`
+	Timeouts   TimeoutsConfig   `yaml:"timeouts"`
+	Sampling   SamplingConfig   `yaml:"sampling"`
+	Detection  DetectionConfig  `yaml:"detection"`
+	Databases  DatabasesConfig  `yaml:"databases"`
+	Include    FilterConfig     `yaml:"include"`
+	Exclude    FilterConfig     `yaml:"exclude"`
+	Output     OutputConfig     `yaml:"output"`
+}
+
+type ConnectionConfig struct {
+	Driver          string `yaml:"driver"`
+	Server          string `yaml:"server"`
+	Port            string `yaml:"port"`
+	Database        string `yaml:"database"`
+	User            string `yaml:"user"`
+	PasswordFile    string `yaml:"password_file"`
+	PasswordEnv     string `yaml:"password_env"`
+	CredentialsFile string `yaml:"credentials_file"`
+
+	Auth        string `yaml:"auth"`
+	Krb5Config  string `yaml:"krb5_config"`
+	Krb5Realm   string `yaml:"krb5_realm"`
+	Keytab      string `yaml:"keytab"`
+	Krb5Cache   string `yaml:"krb5_ccache"`
+	AADClientID string `yaml:"aad_client_id"`
+	AADTenantID string `yaml:"aad_tenant_id"`
+	AADCert     string `yaml:"aad_cert"`
+
+	Encrypt           string `yaml:"encrypt"`
+	TrustServerCert   bool   `yaml:"trust_server_cert"`
+	CAFile            string `yaml:"ca_file"`
+	HostInCert        string `yaml:"host_in_cert"`
+	TLSMin            string `yaml:"tls_min"`
+	RequireEncryption bool   `yaml:"require_encryption"`
+}
+
+type PoolConfig struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+type TimeoutsConfig struct {
+	Connect time.Duration `yaml:"connect"`
+	List    time.Duration `yaml:"list"`
+	Table   time.Duration `yaml:"table"`
+	Column  time.Duration `yaml:"column"`
+}
+
+type SamplingConfig struct {
+	Size int `yaml:"size"`
+}
+
+type DetectionConfig struct {
+	Rules          string  `yaml:"rules"`
+	ConfirmedRatio float64 `yaml:"confirmed_ratio"`
+	PossibleRatio  float64 `yaml:"possible_ratio"`
+}
+
+type DatabasesConfig struct {
+	All     bool     `yaml:"all"`
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+type FilterConfig struct {
+	Schemas []string `yaml:"schemas"`
+	Tables  []string `yaml:"tables"`
+}
+
+type OutputConfig struct {
+	Path      string `yaml:"path"`
+	BatchSize int    `yaml:"batch_size"`
+}
+
+func loadConfig(fileName string) (*Config, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("чтение конфигурации: %v", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("разбор конфигурации %s: %v", fileName, err)
+	}
+
+	return &cfg, nil
+}
+
+// profile возвращает профиль по имени. Если имя не указано, а профиль
+// в файле единственный, используется он.
+func (c *Config) profile(name string) (*Profile, error) {
+	if name == "" {
+		if len(c.Profiles) != 1 {
+			return nil, fmt.Errorf("укажите профиль (--profile), доступны: %s", strings.Join(c.profileNames(), ", "))
+		}
+		for n := range c.Profiles {
+			name = n
+		}
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("профиль %q не найден, доступны: %s", name, strings.Join(c.profileNames(), ", "))
+	}
+
+	return &p, nil
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply переносит значения профиля в опции, кроме тех, что заданы
+// флагами или переменными окружения (keep возвращает true).
+func (p *Profile) apply(o *Options, keep func(flagName, env string) bool) {
+	setString := func(dst *string, v, flagName, env string) {
+		if v != "" && !keep(flagName, env) {
+			*dst = v
+		}
+	}
+	setInt := func(dst *int, v int, flagName string) {
+		if v != 0 && !keep(flagName, "") {
+			*dst = v
+		}
+	}
+	setFloat := func(dst *float64, v float64, flagName string) {
+		if v != 0 && !keep(flagName, "") {
+			*dst = v
+		}
+	}
+	setDuration := func(dst *time.Duration, v time.Duration, flagName string) {
+		if v != 0 && !keep(flagName, "") {
+			*dst = v
+		}
+	}
+	setList := func(dst *[]string, v []string, flagName string) {
+		if len(v) > 0 && !keep(flagName, "") {
+			*dst = v
+		}
+	}
+
+	c := p.Connection
+	setString(&o.Driver, c.Driver, "driver", "PDN_DRIVER")
+	setString(&o.Server, c.Server, "server", "PDN_SERVER")
+	setString(&o.Port, c.Port, "port", "PDN_PORT")
+	setString(&o.Database, c.Database, "database", "PDN_DATABASE")
+	setString(&o.User, c.User, "user", "PDN_USER")
+	setString(&o.PasswordFile, c.PasswordFile, "password-file", "PDN_PASSWORD_FILE")
+	setString(&o.CredentialsFile, c.CredentialsFile, "credentials-file", "PDN_CREDENTIALS_FILE")
+	setString(&o.PasswordEnv, c.PasswordEnv, "", "")
+	setString(&o.Auth.Mode, c.Auth, "auth", "PDN_AUTH")
+	setString(&o.Auth.Krb5Config, c.Krb5Config, "krb5-conf", "")
+	setString(&o.Auth.Krb5Realm, c.Krb5Realm, "krb5-realm", "")
+	setString(&o.Auth.Keytab, c.Keytab, "keytab", "PDN_KEYTAB")
+	setString(&o.Auth.Krb5Cache, c.Krb5Cache, "krb5-ccache", "")
+	setString(&o.Auth.AADClientID, c.AADClientID, "aad-client-id", "PDN_AAD_CLIENT_ID")
+	setString(&o.Auth.AADTenantID, c.AADTenantID, "aad-tenant-id", "PDN_AAD_TENANT_ID")
+	setString(&o.Auth.AADCert, c.AADCert, "aad-cert", "")
+
+	setString(&o.TLS.Encrypt, c.Encrypt, "encrypt", "")
+	setString(&o.TLS.CAFile, c.CAFile, "ca-file", "")
+	setString(&o.TLS.HostInCert, c.HostInCert, "host-in-cert", "")
+	setString(&o.TLS.MinVersion, c.TLSMin, "tls-min", "")
+	if c.TrustServerCert && !keep("trust-server-cert", "") {
+		o.TLS.TrustServerCert = true
+	}
+	if c.RequireEncryption && !keep("require-encryption", "") {
+		o.TLS.RequireEncryption = true
+	}
+
+	setInt(&o.MaxOpenConns, p.Pool.MaxOpenConns, "max-open-conns")
+	setInt(&o.MaxIdleConns, p.Pool.MaxIdleConns, "max-idle-conns")
+	setDuration(&o.ConnMaxLifetime, p.Pool.ConnMaxLifetime, "conn-max-lifetime")
+
+	setDuration(&o.ConnectTimeout, p.Timeouts.Connect, "connect-timeout")
+	setDuration(&o.ListTimeout, p.Timeouts.List, "list-timeout")
+	setDuration(&o.TableTimeout, p.Timeouts.Table, "table-timeout")
+	setDuration(&o.ColumnTimeout, p.Timeouts.Column, "column-timeout")
+
+	setInt(&o.SampleSize, p.Sampling.Size, "sample-size")
+	setString(&o.RulesFile, p.Detection.Rules, "rules", "PDN_RULES")
+	setFloat(&o.ConfirmedRatio, p.Detection.ConfirmedRatio, "confirmed-ratio")
+	setFloat(&o.PossibleRatio, p.Detection.PossibleRatio, "possible-ratio")
+
+	if p.Databases.All && !keep("all-databases", "") {
+		o.AllDatabases = true
+	}
+	setList(&o.IncludeDatabases, p.Databases.Include, "include-db")
+	setList(&o.ExcludeDatabases, p.Databases.Exclude, "exclude-db")
+
+	setList(&o.IncludeSchemas, p.Include.Schemas, "include-schema")
+	setList(&o.IncludeTables, p.Include.Tables, "include-table")
+	setList(&o.ExcludeSchemas, p.Exclude.Schemas, "exclude-schema")
+	setList(&o.ExcludeTables, p.Exclude.Tables, "exclude-table")
+
+	setString(&o.Output, p.Output.Path, "output", "PDN_OUTPUT")
+	setInt(&o.BatchSize, p.Output.BatchSize, "batch-size")
+}
+
+// filterTables оставляет таблицы, подходящие под маски include и не
+// подходящие под exclude. Маска таблицы сравнивается и с именем, и с
+// полным именем schema.table.
+func filterTables(tables []TableInfo, o *Options) []TableInfo {
+	var result []TableInfo
+	for _, t := range tables {
+		full := t.SchemaName + "." + t.TableName
+
+		if len(o.IncludeSchemas) > 0 && !matchAny(o.IncludeSchemas, t.SchemaName) {
+			continue
+		}
+		if matchAny(o.ExcludeSchemas, t.SchemaName) {
+			continue
+		}
+		if len(o.IncludeTables) > 0 && !matchAny(o.IncludeTables, t.TableName, full) {
+			continue
+		}
+		if matchAny(o.ExcludeTables, t.TableName, full) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+func matchAny(patterns []string, names ...string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, strings.ToLower(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
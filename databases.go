@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// databaseErrors — сбои отдельных БД при проверке всех БД сервера.
+type databaseErrors struct {
+	total  int
+	failed []string
+}
+
+// add учитывает сбой БД database и пишет его в лог.
+func (e *databaseErrors) add(database string, err error) {
+	log.Printf("⚠ БД %s: %v\n", database, err)
+	e.failed = append(e.failed, fmt.Sprintf("%s: %v", database, err))
+}
+
+// err возвращает e, если хотя бы одну БД проверить не удалось, иначе nil.
+func (e *databaseErrors) err() error {
+	if len(e.failed) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *databaseErrors) Error() string {
+	return fmt.Sprintf("не удалось проверить БД: %d из %d (%s)", len(e.failed), e.total, strings.Join(e.failed, "; "))
+}
+
+// partial сообщает, что часть БД сервера проверена.
+func (e *databaseErrors) partial() bool {
+	return len(e.failed) < e.total
+}
+
+// scanServer проверяет одну БД из опций либо, при --all-databases, все
+// доступные пользовательские БД экземпляра. Сбои отдельных БД не прерывают
+// проверку остальных и возвращаются вместе как *databaseErrors.
+func scanServer(opts *Options, resultsChan chan<- PDNResult) error {
+	if s, ok := opts.backend.(serverScanner); ok {
+		return s.ScanServer(opts, resultsChan)
+	}
+
+	initialDB := opts.Database
+	if opts.AllDatabases && initialDB == "" {
+		initialDB = opts.dialect.DefaultDatabase()
+	}
+
+	db, err := connectToDB(opts, initialDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
+	security, err := opts.dialect.ConnectionSecurity(ctx, db, opts)
+	cancel()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Защита соединения: %s\n", security)
+
+	serverChan, stop := forwardWithServer(opts.Server, security, resultsChan)
+	defer stop()
+
+	return scanServerDatabases(db, opts, serverChan)
+}
+
+// forwardWithServer возвращает канал, результаты из которого дополняются
+// сведениями о сервере и передаются в resultsChan. stop закрывает канал и
+// дожидается пересылки всех результатов.
+func forwardWithServer(server, security string, resultsChan chan<- PDNResult) (chan<- PDNResult, func()) {
+	serverChan := make(chan PDNResult, 100)
+	done := make(chan struct{})
+	go func() {
+		for r := range serverChan {
+			r.ServerName = server
+			r.ConnectionSecurity = security
+			resultsChan <- r
+		}
+		close(done)
+	}()
+	return serverChan, func() {
+		close(serverChan)
+		<-done
+	}
+}
+
+func scanServerDatabases(db *sql.DB, opts *Options, resultsChan chan<- PDNResult) error {
+	if !opts.AllDatabases {
+		return scanDatabase(db, opts, opts.Database, resultsChan)
+	}
+
+	databases, err := listDatabases(db, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nНайдено %d БД для анализа на сервере %s\n", len(databases), opts.Server)
+
+	errs := &databaseErrors{total: len(databases)}
+	for i, database := range databases {
+		fmt.Printf("\n==== [%d/%d] БД %s ====\n", i+1, len(databases), database)
+
+		dbConn, err := connectToDB(opts, database)
+		if err != nil {
+			errs.add(database, err)
+			continue
+		}
+		if err := scanDatabase(dbConn, opts, database, resultsChan); err != nil {
+			errs.add(database, err)
+		}
+		dbConn.Close()
+	}
+
+	return errs.err()
+}
+
+func scanDatabase(db *sql.DB, opts *Options, database string, resultsChan chan<- PDNResult) error {
+	tables, err := getTablesAndViews(db, opts.dialect, opts.ListTimeout)
+	if err != nil {
+		return err
+	}
+	tables = filterTables(tables, opts)
+	fmt.Printf("\nНайдено %d таблиц/представлений для анализа\n", len(tables))
+
+	analyzeTablesWithBatches(db, opts, database, tables, resultsChan)
+	return nil
+}
+
+// listDatabases возвращает доступные пользовательские БД сервера с учетом
+// масок --include-db/--exclude-db.
+func listDatabases(db *sql.DB, opts *Options) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ListTimeout)
+	defer cancel()
+
+	names, err := opts.dialect.ListDatabases(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка БД: %v", err)
+	}
+
+	return filterDatabases(names, opts), nil
+}
+
+// filterDatabases оставляет БД, подходящие под маски --include-db и не
+// подходящие под --exclude-db.
+func filterDatabases(names []string, opts *Options) []string {
+	var databases []string
+	for _, name := range names {
+		if len(opts.IncludeDatabases) > 0 && !matchAny(opts.IncludeDatabases, name) {
+			continue
+		}
+		if matchAny(opts.ExcludeDatabases, name) {
+			continue
+		}
+		databases = append(databases, name)
+	}
+	return databases
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// detector распознает тип ПДн по значению целиком там, где регулярного
+// выражения недостаточно (поле detector правила). Возвращает вес значения
+// от 0 до 1 — насколько уверенно оно относится к типу ПДн — и вид
+// найденного для отчета; вес 0 — значение не распознано.
+type detector func(value string) (float64, string)
+
+var detectors = map[string]detector{
+	"person_name": detectPersonName,
+	"birth_date":  detectBirthDate,
+	"address":     detectAddress,
+}
+
+func detectorNames() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// wordSet читает словарь: по слову в строке, строки с # — комментарии.
+// Слова приводятся к нижнему регистру, ё заменяется на е.
+func wordSet(data string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[normalizeWord(line)] = true
+	}
+	return set
+}
+
+func normalizeWord(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
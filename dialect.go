@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Backend — общая часть всех источников данных: имя, порт по умолчанию и
+// проверка параметров запуска.
+type Backend interface {
+	Name() string
+	DefaultPort() string
+	// DefaultDatabase — БД для первичного подключения при --all-databases
+	DefaultDatabase() string
+	// Validate проверяет, что параметры запуска поддерживаются СУБД
+	Validate(opts *Options) error
+}
+
+// Dialect инкапсулирует все, что зависит от реляционной СУБД: подключение,
+// запросы к каталогу, экранирование идентификаторов и выборку значений.
+// Общий конвейер анализа работает только через этот интерфейс.
+type Dialect interface {
+	Backend
+
+	DriverName(opts *Options) string
+	DSN(opts *Options, database string) string
+	// ConnectionSecurity возвращает описание защиты текущего соединения
+	ConnectionSecurity(ctx context.Context, db *sql.DB, opts *Options) (string, error)
+
+	ListDatabases(ctx context.Context, db *sql.DB) ([]string, error)
+	ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error)
+	ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error)
+
+	QuoteIdent(name string) string
+	// SampleValues возвращает до limit непустых значений колонки в виде строк
+	SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error)
+}
+
+// serverScanner реализуют источники, которые работают не через database/sql
+// и сами обходят объекты сервера, передавая результаты в resultsChan.
+type serverScanner interface {
+	Backend
+	ScanServer(opts *Options, resultsChan chan<- PDNResult) error
+}
+
+var dialects = map[string]Backend{
+	"mssql":    mssqlDialect{},
+	"mongodb":  mongoBackend{},
+	"mysql":    mysqlDialect{},
+	"oracle":   oracleDialect{},
+	"postgres": postgresDialect{},
+	"sqlite":   sqliteDialect{},
+}
+
+var dialectAliases = map[string]string{
+	"sqlserver":  "mssql",
+	"postgresql": "postgres",
+	"pg":         "postgres",
+	"mariadb":    "mysql",
+	"sqlite3":    "sqlite",
+	"mongo":      "mongodb",
+}
+
+// localDialect реализуют файловые СУБД, которым не нужны сервер, порт и
+// учетные данные.
+type localDialect interface {
+	Local() bool
+}
+
+func isLocalDialect(d Backend) bool {
+	l, ok := d.(localDialect)
+	return ok && l.Local()
+}
+
+func dialectByName(name string) (Backend, error) {
+	name = strings.ToLower(name)
+	if alias, ok := dialectAliases[name]; ok {
+		name = alias
+	}
+	if d, ok := dialects[name]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("неизвестная СУБД %q, доступны: %s", name, strings.Join(dialectNames(), ", "))
+}
+
+func dialectNames() []string {
+	names := make([]string, 0, len(dialects))
+	for n := range dialects {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func queryTables(ctx context.Context, db *sql.DB, query string, args ...any) ([]TableInfo, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения таблиц: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []TableInfo
+	for rows.Next() {
+		var ti TableInfo
+		if err := rows.Scan(&ti.SchemaName, &ti.TableName, &ti.TableType); err != nil {
+			return nil, fmt.Errorf("чтение данных таблицы: %v", err)
+		}
+		tables = append(tables, ti)
+	}
+
+	return tables, rows.Err()
+}
+
+func queryColumns(ctx context.Context, db *sql.DB, query string, args ...any) ([]ColumnInfo, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("запрос колонок: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var ci ColumnInfo
+		if err := rows.Scan(&ci.ColumnName, &ci.DataType); err != nil {
+			return nil, fmt.Errorf("чтение колонки: %v", err)
+		}
+		columns = append(columns, ci)
+	}
+
+	return columns, rows.Err()
+}
+
+// queryStrings выполняет запрос, возвращающий одну строковую колонку;
+// NULL пропускаются.
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var val sql.NullString
+		if err := rows.Scan(&val); err != nil {
+			return nil, err
+		}
+		if val.Valid {
+			values = append(values, val.String)
+		}
+	}
+
+	return values, rows.Err()
+}
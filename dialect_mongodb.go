@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongoDocumentSample — сколько документов коллекции читается для вывода
+// схемы; значения каждого поля затем ограничиваются --sample-size.
+const mongoDocumentSample = 200
+
+// mongoSystemDatabases не проверяются при --all-databases.
+var mongoSystemDatabases = []string{"admin", "config", "local"}
+
+// mongoBackend проверяет документы MongoDB. Коллекция считается таблицей,
+// а пути полей вложенных документов (contacts.phones[].number) — колонками.
+type mongoBackend struct{}
+
+func (mongoBackend) Name() string            { return "mongodb" }
+func (mongoBackend) DefaultPort() string     { return "27017" }
+func (mongoBackend) DefaultDatabase() string { return "" }
+
+func (mongoBackend) Validate(opts *Options) error {
+	if opts.Auth.Mode != authSQL {
+		return fmt.Errorf("для mongodb поддерживается только --auth %s", authSQL)
+	}
+	if opts.TLS.HostInCert != "" || opts.TLS.MinVersion != "" {
+		return errors.New("для mongodb не поддерживаются --host-in-cert и --tls-min")
+	}
+	return opts.TLS.validate()
+}
+
+// mongoURI собирает строку подключения; учетные данные проверяются по БД
+// admin, как принято для пользователей уровня сервера.
+func mongoURI(opts *Options) string {
+	query := url.Values{}
+	if opts.User != "" {
+		query.Set("authSource", "admin")
+	}
+	switch opts.TLS.effectiveEncrypt() {
+	case encryptTrue, encryptStrict:
+		query.Set("tls", "true")
+		if opts.TLS.TrustServerCert {
+			query.Set("tlsInsecure", "true")
+		}
+		if opts.TLS.CAFile != "" {
+			query.Set("tlsCAFile", opts.TLS.CAFile)
+		}
+	case encryptDisable, encryptFalse:
+		query.Set("tls", "false")
+	}
+
+	u := &url.URL{
+		Scheme:   "mongodb",
+		Host:     net.JoinHostPort(opts.Server, opts.Port),
+		Path:     "/",
+		RawQuery: query.Encode(),
+	}
+	if opts.User != "" {
+		u.User = url.UserPassword(opts.User, opts.Password)
+	}
+	return u.String()
+}
+
+// mongoSecurity описывает защиту соединения по параметрам запуска: при
+// tls=true драйвер не устанавливает соединение без TLS.
+func mongoSecurity(opts *Options) string {
+	switch opts.TLS.effectiveEncrypt() {
+	case encryptTrue, encryptStrict:
+	default:
+		return "без шифрования данных"
+	}
+	switch {
+	case opts.TLS.TrustServerCert:
+		return "TLS, сертификат не проверяется"
+	case opts.TLS.CAFile != "":
+		return "TLS, сертификат проверен по " + opts.TLS.CAFile
+	}
+	return "TLS, сертификат проверен"
+}
+
+func (mongoBackend) ScanServer(opts *Options, resultsChan chan<- PDNResult) error {
+	clientOpts := options.Client().
+		ApplyURI(mongoURI(opts)).
+		SetAppName("pdn_checker").
+		SetConnectTimeout(opts.ConnectTimeout).
+		SetServerSelectionTimeout(opts.ConnectTimeout).
+		SetMaxPoolSize(uint64(opts.MaxOpenConns))
+
+	client, err := mongo.Connect(clientOpts)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
+	err = client.Ping(ctx, nil)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки подключения: %v", err)
+	}
+	fmt.Printf("✓ Успешное подключение к серверу %s\n", opts.Server)
+
+	security := mongoSecurity(opts)
+	fmt.Printf("Защита соединения: %s\n", security)
+
+	serverChan, stop := forwardWithServer(opts.Server, security, resultsChan)
+	defer stop()
+
+	databases := []string{opts.Database}
+	if opts.AllDatabases {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.ListTimeout)
+		names, err := client.ListDatabaseNames(ctx, bson.D{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("ошибка получения списка БД: %v", err)
+		}
+
+		var userNames []string
+		for _, name := range names {
+			if !contains(mongoSystemDatabases, name) {
+				userNames = append(userNames, name)
+			}
+		}
+		databases = filterDatabases(userNames, opts)
+		fmt.Printf("\nНайдено %d БД для анализа на сервере %s\n", len(databases), opts.Server)
+	}
+
+	errs := &databaseErrors{total: len(databases)}
+	for i, database := range databases {
+		if opts.AllDatabases {
+			fmt.Printf("\n==== [%d/%d] БД %s ====\n", i+1, len(databases), database)
+		}
+		if err := scanMongoDatabase(client.Database(database), opts, serverChan); err != nil {
+			if !opts.AllDatabases {
+				return err
+			}
+			errs.add(database, err)
+		}
+	}
+
+	return errs.err()
+}
+
+func scanMongoDatabase(db *mongo.Database, opts *Options, resultsChan chan<- PDNResult) error {
+	fmt.Println("\nПолучение списка коллекций...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ListTimeout)
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("ошибка получения коллекций: %v", err)
+	}
+
+	var tables []TableInfo
+	for _, spec := range specs {
+		if strings.HasPrefix(spec.Name, "system.") {
+			continue
+		}
+		tables = append(tables, TableInfo{
+			SchemaName: db.Name(),
+			TableName:  spec.Name,
+			TableType:  strings.ToUpper(spec.Type),
+		})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].TableName < tables[j].TableName })
+	tables = filterTables(tables, opts)
+	fmt.Printf("\nНайдено %d коллекций для анализа\n", len(tables))
+
+	for i, table := range tables {
+		fmt.Printf("\n[%d/%d] Анализ %s.%s (%s)...\n",
+			i+1, len(tables), table.SchemaName, table.TableName, table.TableType)
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.TableTimeout)
+		schema, err := sampleMongoCollection(ctx, db.Collection(table.TableName), opts.SampleSize)
+		cancel()
+		if err != nil {
+			log.Printf("⚠ Ошибка чтения документов: %v - пропускаем\n", err)
+			resultsChan <- createTableTimeoutResult(db.Name(), table)
+			continue
+		}
+
+		fmt.Printf("  Найдено %d полей\n", len(schema.columns))
+		for _, col := range schema.columns {
+			fmt.Printf("  - %s (%s)\n", col.ColumnName, col.DataType)
+		}
+
+		var tableResults []PDNResult
+		for _, col := range schema.columns {
+			tableResults = append(tableResults, classifyColumn(opts.rules, db.Name(), table, col, schema.values[col.ColumnName])...)
+		}
+		finishTable(opts.rules, tableResults, resultsChan)
+	}
+
+	return nil
+}
+
+// sampleMongoCollection читает случайную выборку документов через $sample
+// и выводит по ней схему коллекции.
+func sampleMongoCollection(ctx context.Context, coll *mongo.Collection, sampleSize int) (*documentSchema, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: mongoDocumentSample}}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	schema := newDocumentSchema(sampleSize)
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		schema.flatten("", doc)
+	}
+
+	return schema, cursor.Err()
+}
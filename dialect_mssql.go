@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	_ "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/azuread"
+	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
+)
+
+type mssqlDialect struct{}
+
+func (mssqlDialect) Name() string            { return "mssql" }
+func (mssqlDialect) DefaultPort() string     { return "1433" }
+func (mssqlDialect) DefaultDatabase() string { return "master" }
+
+func (mssqlDialect) Validate(opts *Options) error {
+	if err := opts.Auth.validate(); err != nil {
+		return err
+	}
+	return opts.TLS.validate()
+}
+
+func (mssqlDialect) DriverName(opts *Options) string {
+	switch opts.Auth.Mode {
+	case authAADPassword, authAADServicePrincipal:
+		return azuread.DriverName
+	}
+	return "sqlserver"
+}
+
+// DSN собирает строку подключения в URL-формате, чтобы пароль с символами
+// ';', '=' и т.п. экранировался, а не ломал строку.
+func (mssqlDialect) DSN(opts *Options, database string) string {
+	host, instance, _ := strings.Cut(opts.Server, "\\")
+
+	query := url.Values{}
+	query.Set("database", database)
+
+	user := opts.User
+	a := opts.Auth
+	switch a.Mode {
+	case authKerberos:
+		query.Set("authenticator", "krb5")
+		query.Set("krb5-configfile", a.Krb5Config)
+		if a.Krb5Realm != "" {
+			query.Set("krb5-realm", a.Krb5Realm)
+		}
+		if a.Keytab != "" {
+			query.Set("krb5-keytabfile", a.Keytab)
+		}
+		if a.Krb5Cache != "" {
+			query.Set("krb5-credcachefile", a.Krb5Cache)
+		}
+	case authAADPassword:
+		query.Set("fedauth", azuread.ActiveDirectoryPassword)
+		query.Set("applicationclientid", a.AADClientID)
+	case authAADServicePrincipal:
+		query.Set("fedauth", azuread.ActiveDirectoryServicePrincipal)
+		user = a.AADClientID
+		if a.AADTenantID != "" {
+			user += "@" + a.AADTenantID
+		}
+		if a.AADCert != "" {
+			query.Set("clientcertpath", a.AADCert)
+		}
+	}
+
+	opts.TLS.applyTo(query)
+
+	// С портом go-mssqldb не обращается к SQL Browser и игнорирует имя
+	// экземпляра, поэтому порт указывается, только если он задан
+	if opts.Port != "" {
+		host = net.JoinHostPort(host, opts.Port)
+	}
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		Host:     host,
+		Path:     instance,
+		RawQuery: query.Encode(),
+	}
+	if user != "" || opts.Password != "" {
+		u.User = url.UserPassword(user, opts.Password)
+	}
+	return u.String()
+}
+
+// ConnectionSecurity запрашивает у сервера фактическое состояние шифрования
+// текущего соединения. При --require-encryption нешифрованное соединение
+// считается ошибкой.
+func (mssqlDialect) ConnectionSecurity(ctx context.Context, db *sql.DB, opts *Options) (string, error) {
+	t := &opts.TLS
+
+	var encryptOption, authScheme string
+	err := db.QueryRowContext(ctx, `
+		SELECT encrypt_option, auth_scheme
+		FROM sys.dm_exec_connections
+		WHERE session_id = @@SPID
+	`).Scan(&encryptOption, &authScheme)
+	if err != nil {
+		// Без VIEW SERVER STATE представление недоступно; при обязательном
+		// шифровании драйвер сам не установит соединение без TLS
+		if t.RequireEncryption {
+			return describeSecurity(t, "TRUE", ""), nil
+		}
+		return "неизвестно (нет доступа к sys.dm_exec_connections)", nil
+	}
+
+	if t.RequireEncryption && !strings.EqualFold(encryptOption, "TRUE") {
+		return "", errors.New("сервер не согласовал шифрование соединения, а указан --require-encryption")
+	}
+
+	return describeSecurity(t, encryptOption, authScheme), nil
+}
+
+// ListDatabases возвращает пользовательские БД в состоянии ONLINE, к которым
+// у логина есть доступ.
+func (mssqlDialect) ListDatabases(ctx context.Context, db *sql.DB) ([]string, error) {
+	return queryStrings(ctx, db, `
+		SELECT name
+		FROM sys.databases
+		WHERE database_id > 4
+			AND state_desc = 'ONLINE'
+			AND is_distributor = 0
+			AND HAS_DBACCESS(name) = 1
+		ORDER BY name
+	`)
+}
+
+func (mssqlDialect) ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error) {
+	return queryTables(ctx, db, `
+		SELECT s.name AS schema_name, t.name AS table_name, t.type_desc AS table_type
+		FROM sys.tables t
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		UNION ALL
+		SELECT s.name AS schema_name, v.name AS view_name, 'VIEW' AS table_type
+		FROM sys.views v
+		INNER JOIN sys.schemas s ON v.schema_id = s.schema_id
+	`)
+}
+
+func (mssqlDialect) ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error) {
+	return queryColumns(ctx, db, `
+		SELECT c.name AS column_name, tp.name AS data_type
+		FROM sys.columns c
+		JOIN sys.objects o ON c.object_id = o.object_id
+		JOIN sys.schemas s ON o.schema_id = s.schema_id
+		JOIN sys.types tp ON c.user_type_id = tp.user_type_id
+		WHERE s.name = @schema AND o.name = @table
+	`, sql.Named("schema", table.SchemaName), sql.Named("table", table.TableName))
+}
+
+func (mssqlDialect) QuoteIdent(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (d mssqlDialect) SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error) {
+	col := d.QuoteIdent(column.ColumnName)
+	from := d.QuoteIdent(table.SchemaName) + "." + d.QuoteIdent(table.TableName)
+
+	// Пытаемся получить значения как строку
+	query := fmt.Sprintf(`
+		SELECT TOP %d TRY_CAST(%s AS NVARCHAR(MAX)) AS sample_value
+		FROM %s WITH (NOLOCK)
+		WHERE %s IS NOT NULL AND TRY_CAST(%s AS NVARCHAR(MAX)) != ''
+	`, limit, col, from, col, col)
+
+	values, err := queryStrings(ctx, db, query)
+	if err != nil {
+		// Если ошибка, пробуем альтернативный вариант с CONVERT
+		query = fmt.Sprintf(`
+			SELECT TOP %d CONVERT(NVARCHAR(MAX), %s) AS sample_value
+			FROM %s WITH (NOLOCK)
+			WHERE %s IS NOT NULL AND CONVERT(NVARCHAR(MAX), %s) != ''
+		`, limit, col, from, col, col)
+
+		values, err = queryStrings(ctx, db, query)
+		if err != nil {
+			return nil, fmt.Errorf("запрос значений: %v", err)
+		}
+	}
+
+	// Если нет значений, проверяем, есть ли вообще данные в колонке
+	if len(values) == 0 {
+		checkQuery := fmt.Sprintf(`
+			SELECT TOP 1 1
+			FROM %s WITH (NOLOCK)
+			WHERE %s IS NOT NULL AND
+				  (TRY_CAST(%s AS NVARCHAR(MAX)) IS NOT NULL AND
+				   TRY_CAST(%s AS NVARCHAR(MAX)) != '')
+		`, from, col, col, col)
+
+		var exists int
+		err := db.QueryRowContext(ctx, checkQuery).Scan(&exists)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil // Колонка пустая или содержит только NULL/пустые значения
+			}
+
+			// Пробуем альтернативный вариант проверки
+			checkQuery = fmt.Sprintf(`
+				SELECT TOP 1 1
+				FROM %s WITH (NOLOCK)
+				WHERE %s IS NOT NULL
+			`, from, col)
+
+			err = db.QueryRowContext(ctx, checkQuery).Scan(&exists)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("проверка наличия данных: %v", err)
+			}
+		}
+	}
+
+	return values, nil
+}
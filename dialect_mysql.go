@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlLockWaitTimeout ограничивает ожидание блокировок метаданных (секунды):
+// выборка не должна зависать за чужим ALTER TABLE и держать его очередь.
+const mysqlLockWaitTimeout = "10"
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// mysqlDialect обслуживает MySQL и MariaDB.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string            { return "mysql" }
+func (mysqlDialect) DefaultPort() string     { return "3306" }
+func (mysqlDialect) DefaultDatabase() string { return "information_schema" }
+
+// Validate проверяет собственную TLS-конфигурацию, поэтому ошибки чтения
+// --ca-file выявляются до подключения. В драйвере она регистрируется при
+// сборке DSN: сервер может быть еще не задан (его запросит promptMissing).
+func (mysqlDialect) Validate(opts *Options) error {
+	if opts.Auth.Mode != authSQL {
+		return fmt.Errorf("для mysql поддерживается только --auth %s", authSQL)
+	}
+	if err := opts.TLS.validate(); err != nil {
+		return err
+	}
+	if mysqlTLSConfigName(opts) != "" {
+		if _, err := mysqlTLSConfig(opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mysqlDialect) DriverName(*Options) string { return "mysql" }
+
+func (mysqlDialect) DSN(opts *Options, database string) string {
+	cfg := mysql.NewConfig()
+	cfg.User = opts.User
+	cfg.Passwd = opts.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(opts.Server, opts.Port)
+	cfg.DBName = database
+	cfg.Timeout = opts.ConnectTimeout
+	cfg.TLSConfig = mysqlTLSMode(opts)
+	// Параметры TLS проверены в Validate; если конфигурацию все же не удалось
+	// собрать, драйвер не найдет ее по имени и подключение не состоится
+	if name := mysqlTLSConfigName(opts); name != "" {
+		if tlsCfg, err := mysqlTLSConfig(opts); err == nil {
+			mysql.RegisterTLSConfig(name, tlsCfg)
+		}
+	}
+	cfg.Params = map[string]string{
+		"charset":           "utf8mb4",
+		"lock_wait_timeout": mysqlLockWaitTimeout,
+	}
+	return cfg.FormatDSN()
+}
+
+// mysqlTLSMode переводит режим --encrypt в параметр tls драйвера.
+func mysqlTLSMode(opts *Options) string {
+	t := &opts.TLS
+	switch t.effectiveEncrypt() {
+	case encryptDisable:
+		return "false"
+	case encryptTrue, encryptStrict:
+		if t.TrustServerCert {
+			return "skip-verify"
+		}
+		if name := mysqlTLSConfigName(opts); name != "" {
+			return name
+		}
+		return "true"
+	}
+	return "preferred"
+}
+
+// mysqlTLSConfigName возвращает имя собственной TLS-конфигурации, если
+// проверка сертификата требует параметров сверх системных корней.
+func mysqlTLSConfigName(opts *Options) string {
+	t := &opts.TLS
+	switch t.effectiveEncrypt() {
+	case encryptTrue, encryptStrict:
+	default:
+		return ""
+	}
+	if t.TrustServerCert || (t.CAFile == "" && t.HostInCert == "" && t.MinVersion == "") {
+		return ""
+	}
+	return "pdn-" + net.JoinHostPort(opts.Server, opts.Port)
+}
+
+func mysqlTLSConfig(opts *Options) (*tls.Config, error) {
+	t := &opts.TLS
+	cfg := &tls.Config{ServerName: opts.Server}
+	if t.HostInCert != "" {
+		cfg.ServerName = t.HostInCert
+	}
+	if t.MinVersion != "" {
+		v, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("неизвестная версия TLS %q", t.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("чтение --ca-file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("в %s не найдено сертификатов", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+func (mysqlDialect) ConnectionSecurity(ctx context.Context, db *sql.DB, opts *Options) (string, error) {
+	rows, err := db.QueryContext(ctx, `SHOW SESSION STATUS WHERE Variable_name IN ('Ssl_version', 'Ssl_cipher')`)
+	if err != nil {
+		return "неизвестно (нет доступа к статусу сессии)", nil
+	}
+	defer rows.Close()
+
+	status := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return "", err
+		}
+		status[strings.ToLower(name)] = value
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	if status["ssl_cipher"] == "" {
+		if opts.TLS.RequireEncryption {
+			return "", errors.New("сервер не согласовал шифрование соединения, а указан --require-encryption")
+		}
+		return "без шифрования данных", nil
+	}
+
+	parts := []string{strings.TrimSpace(status["ssl_version"] + " " + status["ssl_cipher"])}
+	switch mode := mysqlTLSMode(opts); {
+	case mode == "preferred" || mode == "skip-verify":
+		parts = append(parts, "сертификат не проверяется")
+	case opts.TLS.CAFile != "":
+		parts = append(parts, "сертификат проверен по "+opts.TLS.CAFile)
+	default:
+		parts = append(parts, "сертификат проверен")
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// ListDatabases возвращает схемы, кроме системных. information_schema
+// показывает только схемы, на которые у пользователя есть права.
+func (mysqlDialect) ListDatabases(ctx context.Context, db *sql.DB) ([]string, error) {
+	return queryStrings(ctx, db, `
+		SELECT SCHEMA_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')
+		ORDER BY SCHEMA_NAME
+	`)
+}
+
+// ListObjects возвращает таблицы и представления текущей БД; в MySQL схема и
+// БД совпадают, поэтому SchemaName равен имени БД.
+func (mysqlDialect) ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error) {
+	return queryTables(ctx, db, `
+		SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+	`)
+}
+
+func (mysqlDialect) ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error) {
+	return queryColumns(ctx, db, `
+		SELECT COLUMN_NAME, DATA_TYPE
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`, table.SchemaName, table.TableName)
+}
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// SampleValues читает первые непустые значения обычным SELECT ... LIMIT:
+// в InnoDB это неблокирующее согласованное чтение. Подсказка
+// MAX_EXECUTION_TIME останавливает запрос на сервере по истечении таймаута
+// колонки (MariaDB воспринимает ее как комментарий).
+func (d mysqlDialect) SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error) {
+	col := d.QuoteIdent(column.ColumnName)
+	from := d.QuoteIdent(table.SchemaName) + "." + d.QuoteIdent(table.TableName)
+
+	hint := ""
+	if deadline, ok := ctx.Deadline(); ok {
+		if ms := time.Until(deadline).Milliseconds(); ms > 0 {
+			hint = fmt.Sprintf("/*+ MAX_EXECUTION_TIME(%d) */", ms)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s CAST(%s AS CHAR)
+		FROM %s
+		WHERE %s IS NOT NULL AND CAST(%s AS CHAR) <> ''
+		LIMIT %d
+	`, hint, col, from, col, col, limit)
+
+	values, err := queryStrings(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("запрос значений: %v", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// TestMySQLTLSConfigAfterPrompt проверяет, что собственная TLS-конфигурация
+// строится для сервера, заданного уже после разбора параметров (ввод в
+// promptMissing или запись списка серверов).
+func TestMySQLTLSConfigAfterPrompt(t *testing.T) {
+	opts := &Options{
+		Driver: "mysql",
+		Auth:   AuthOptions{Mode: authSQL},
+		TLS: TLSOptions{
+			Encrypt:    encryptTrue,
+			CAFile:     writeTestCA(t),
+			MinVersion: "1.2",
+		},
+	}
+	if err := opts.resolveDialect(); err != nil {
+		t.Fatalf("resolveDialect: %v", err)
+	}
+
+	opts.Server = "db01.corp.local"
+	cfg, err := mysql.ParseDSN(opts.dialect.DSN(opts, "shop"))
+	if err != nil {
+		t.Fatalf("ParseDSN: %v", err)
+	}
+	if cfg.TLS == nil {
+		t.Fatalf("TLS-конфигурация %q не зарегистрирована", cfg.TLSConfig)
+	}
+	if cfg.TLS.ServerName != opts.Server {
+		t.Errorf("ServerName = %q, ожидался %q", cfg.TLS.ServerName, opts.Server)
+	}
+	if cfg.TLS.RootCAs == nil || cfg.TLS.MinVersion != tls.VersionTLS12 {
+		t.Errorf("не применены --ca-file или --tls-min: %+v", cfg.TLS)
+	}
+
+	opts.TLS.HostInCert = "mysql.corp.local"
+	cfg, err = mysql.ParseDSN(opts.dialect.DSN(opts, "shop"))
+	if err != nil {
+		t.Fatalf("ParseDSN: %v", err)
+	}
+	if cfg.TLS == nil || cfg.TLS.ServerName != opts.TLS.HostInCert {
+		t.Errorf("не применен --host-in-cert: %+v", cfg.TLS)
+	}
+}
+
+func TestMySQLValidateCAFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, caFile := range []string{missing, notPEM} {
+		opts := &Options{
+			Driver: "mysql",
+			Auth:   AuthOptions{Mode: authSQL},
+			TLS:    TLSOptions{Encrypt: encryptTrue, CAFile: caFile},
+		}
+		if err := opts.resolveDialect(); err == nil {
+			t.Errorf("%s: ожидалась ошибка --ca-file", caFile)
+		}
+	}
+}
+
+// writeTestCA создает самоподписанный сертификат CA в формате PEM.
+func writeTestCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pdn test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
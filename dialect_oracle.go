@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	_ "github.com/sijms/go-ora/v2"
+)
+
+// oracleSampleThreshold — начиная с этой оценки числа строк (ALL_TABLES.NUM_ROWS)
+// значения выбираются через SAMPLE, а не первыми строками таблицы.
+const oracleSampleThreshold = 100000
+
+// oracleDialect обслуживает Oracle Database 12c и новее; --database задает
+// имя сервиса (service name), а схемами считаются владельцы объектов.
+type oracleDialect struct{}
+
+func (oracleDialect) Name() string            { return "oracle" }
+func (oracleDialect) DefaultPort() string     { return "1521" }
+func (oracleDialect) DefaultDatabase() string { return "" }
+
+func (oracleDialect) Validate(opts *Options) error {
+	if opts.Auth.Mode != authSQL {
+		return fmt.Errorf("для oracle поддерживается только --auth %s", authSQL)
+	}
+	if opts.AllDatabases {
+		return errors.New("для oracle укажите сервис в --database; схемы отбираются через --include-schema/--exclude-schema")
+	}
+	if opts.TLS.CAFile != "" || opts.TLS.HostInCert != "" || opts.TLS.MinVersion != "" {
+		return errors.New("для oracle не поддерживаются --ca-file, --host-in-cert и --tls-min")
+	}
+	if _, err := strconv.Atoi(opts.Port); err != nil {
+		return fmt.Errorf("некорректный порт %q", opts.Port)
+	}
+	return opts.TLS.validate()
+}
+
+func (oracleDialect) DriverName(*Options) string { return "oracle" }
+
+func (oracleDialect) DSN(opts *Options, database string) string {
+	query := url.Values{}
+	query.Set("CONNECTION TIMEOUT", strconv.Itoa(int(opts.ConnectTimeout.Seconds())))
+	switch opts.TLS.effectiveEncrypt() {
+	case encryptTrue, encryptStrict:
+		query.Set("SSL", "true")
+		query.Set("SSL VERIFY", strconv.FormatBool(!opts.TLS.TrustServerCert))
+	}
+
+	u := &url.URL{
+		Scheme:   "oracle",
+		User:     url.UserPassword(opts.User, opts.Password),
+		Host:     net.JoinHostPort(opts.Server, opts.Port),
+		Path:     "/" + database,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// ConnectionSecurity определяет TLS по протоколу сессии: tcps означает
+// соединение через TLS, tcp — без него.
+func (oracleDialect) ConnectionSecurity(ctx context.Context, db *sql.DB, opts *Options) (string, error) {
+	var protocol string
+	err := db.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'NETWORK_PROTOCOL') FROM DUAL`).Scan(&protocol)
+	if err != nil {
+		return "неизвестно (нет доступа к USERENV)", nil
+	}
+
+	if !strings.EqualFold(protocol, "tcps") {
+		if opts.TLS.RequireEncryption {
+			return "", errors.New("сервер не согласовал шифрование соединения, а указан --require-encryption")
+		}
+		return "без шифрования данных", nil
+	}
+
+	if opts.TLS.TrustServerCert {
+		return "TLS, сертификат не проверяется", nil
+	}
+	return "TLS, сертификат проверен", nil
+}
+
+func (oracleDialect) ListDatabases(context.Context, *sql.DB) ([]string, error) {
+	return nil, errors.New("для oracle список БД не поддерживается")
+}
+
+// ListObjects возвращает таблицы и представления всех доступных
+// пользователю схем, кроме поставляемых Oracle (ORACLE_MAINTAINED).
+func (oracleDialect) ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error) {
+	return queryTables(ctx, db, `
+		SELECT t.owner, t.table_name, 'TABLE'
+		FROM all_tables t
+		JOIN all_users u ON u.username = t.owner
+		WHERE u.oracle_maintained = 'N'
+			AND t.nested = 'NO'
+			AND t.secondary = 'N'
+			AND t.dropped = 'NO'
+		UNION ALL
+		SELECT v.owner, v.view_name, 'VIEW'
+		FROM all_views v
+		JOIN all_users u ON u.username = v.owner
+		WHERE u.oracle_maintained = 'N'
+	`)
+}
+
+func (oracleDialect) ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error) {
+	return queryColumns(ctx, db, `
+		SELECT column_name, data_type
+		FROM all_tab_columns
+		WHERE owner = :1 AND table_name = :2
+		ORDER BY column_id
+	`, table.SchemaName, table.TableName)
+}
+
+func (oracleDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// oracleTextExpr приводит колонку к строке по ее типу. Для типов, которые
+// нельзя прочитать как текст (BLOB, LONG, RAW и т.п.), возвращается пустая
+// строка.
+func oracleTextExpr(col, dataType string) string {
+	switch t := strings.ToUpper(dataType); {
+	case t == "CLOB" || t == "NCLOB":
+		return fmt.Sprintf("DBMS_LOB.SUBSTR(%s, 4000, 1)", col)
+	case t == "DATE":
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD')", col)
+	case strings.HasPrefix(t, "TIMESTAMP"):
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD HH24:MI:SS')", col)
+	case t == "CHAR" || t == "NCHAR" || t == "VARCHAR2" || t == "NVARCHAR2" || t == "VARCHAR":
+		return col
+	case t == "NUMBER" || t == "FLOAT" || t == "BINARY_FLOAT" || t == "BINARY_DOUBLE" || t == "INTEGER":
+		return fmt.Sprintf("TO_CHAR(%s)", col)
+	}
+	return ""
+}
+
+// SampleValues для больших таблиц читает случайные блоки через SAMPLE BLOCK,
+// чтобы не брать только первые вставленные строки. Если выборка пуста,
+// читаются первые строки. Пустая строка в Oracle равна NULL, поэтому
+// проверка на пустую строку не требуется.
+func (d oracleDialect) SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error) {
+	expr := oracleTextExpr(d.QuoteIdent(column.ColumnName), column.DataType)
+	if expr == "" {
+		return nil, nil
+	}
+	col := d.QuoteIdent(column.ColumnName)
+	from := d.QuoteIdent(table.SchemaName) + "." + d.QuoteIdent(table.TableName)
+
+	if table.TableType == "TABLE" {
+		var estimate sql.NullFloat64
+		err := db.QueryRowContext(ctx, `
+			SELECT num_rows
+			FROM all_tables
+			WHERE owner = :1 AND table_name = :2
+		`, table.SchemaName, table.TableName).Scan(&estimate)
+		if err == nil && estimate.Valid && estimate.Float64 > oracleSampleThreshold {
+			// Берем с запасом: часть значений может оказаться NULL
+			percent := min(99.999999, max(0.000001, float64(limit)*10*100/estimate.Float64))
+			query := fmt.Sprintf(`
+				SELECT %s
+				FROM %s SAMPLE BLOCK (%f)
+				WHERE %s IS NOT NULL
+				FETCH FIRST %d ROWS ONLY
+			`, expr, from, percent, col, limit)
+
+			values, err := queryStrings(ctx, db, query)
+			if err == nil && len(values) > 0 {
+				return values, nil
+			}
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE %s IS NOT NULL
+		FETCH FIRST %d ROWS ONLY
+	`, expr, from, col, limit)
+
+	values, err := queryStrings(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("запрос значений: %v", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresSampleThreshold — начиная с этой оценки числа строк значения
+// выбираются через TABLESAMPLE, а не первыми строками таблицы.
+const postgresSampleThreshold = 100000
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string            { return "postgres" }
+func (postgresDialect) DefaultPort() string     { return "5432" }
+func (postgresDialect) DefaultDatabase() string { return "postgres" }
+
+func (postgresDialect) Validate(opts *Options) error {
+	if opts.Auth.Mode != authSQL {
+		return fmt.Errorf("для postgres поддерживается только --auth %s", authSQL)
+	}
+	if opts.TLS.HostInCert != "" || opts.TLS.MinVersion != "" {
+		return errors.New("для postgres не поддерживаются --host-in-cert и --tls-min")
+	}
+	return opts.TLS.validate()
+}
+
+func (postgresDialect) DriverName(*Options) string { return "pgx" }
+
+func (postgresDialect) DSN(opts *Options, database string) string {
+	query := url.Values{}
+	query.Set("sslmode", postgresSSLMode(&opts.TLS))
+	if opts.TLS.CAFile != "" {
+		query.Set("sslrootcert", opts.TLS.CAFile)
+	}
+	query.Set("application_name", "pdn_checker")
+
+	u := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(opts.User, opts.Password),
+		Host:     net.JoinHostPort(opts.Server, opts.Port),
+		Path:     "/" + database,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// postgresSSLMode переводит режим --encrypt в sslmode libpq.
+func postgresSSLMode(t *TLSOptions) string {
+	switch t.effectiveEncrypt() {
+	case encryptDisable:
+		return "disable"
+	case encryptTrue, encryptStrict:
+		if t.TrustServerCert {
+			return "require"
+		}
+		return "verify-full"
+	}
+	return "prefer"
+}
+
+func (postgresDialect) ConnectionSecurity(ctx context.Context, db *sql.DB, opts *Options) (string, error) {
+	var ssl bool
+	var version, cipher string
+	err := db.QueryRowContext(ctx, `
+		SELECT ssl, COALESCE(version, ''), COALESCE(cipher, '')
+		FROM pg_stat_ssl
+		WHERE pid = pg_backend_pid()
+	`).Scan(&ssl, &version, &cipher)
+	if err != nil {
+		return "неизвестно (нет доступа к pg_stat_ssl)", nil
+	}
+
+	if !ssl {
+		if opts.TLS.RequireEncryption {
+			return "", errors.New("сервер не согласовал шифрование соединения, а указан --require-encryption")
+		}
+		return "без шифрования данных", nil
+	}
+
+	parts := []string{strings.TrimSpace(version + " " + cipher)}
+	switch mode := postgresSSLMode(&opts.TLS); {
+	case mode == "verify-full" && opts.TLS.CAFile != "":
+		parts = append(parts, "сертификат проверен по "+opts.TLS.CAFile)
+	case mode == "verify-full":
+		parts = append(parts, "сертификат проверен")
+	default:
+		parts = append(parts, "сертификат не проверяется")
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func (postgresDialect) ListDatabases(ctx context.Context, db *sql.DB) ([]string, error) {
+	return queryStrings(ctx, db, `
+		SELECT datname
+		FROM pg_database
+		WHERE NOT datistemplate
+			AND datallowconn
+			AND has_database_privilege(datname, 'CONNECT')
+		ORDER BY datname
+	`)
+}
+
+func (postgresDialect) ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error) {
+	return queryTables(ctx, db, `
+		SELECT table_schema, table_name, table_type
+		FROM information_schema.tables
+		WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+			AND table_schema NOT LIKE 'pg\_toast%'
+			AND table_schema NOT LIKE 'pg\_temp%'
+		UNION ALL
+		SELECT schemaname, matviewname, 'MATERIALIZED VIEW'
+		FROM pg_matviews
+	`)
+}
+
+// ListColumns читает pg_catalog, а не information_schema.columns, чтобы
+// получить колонки и материализованных представлений.
+func (postgresDialect) ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error) {
+	return queryColumns(ctx, db, `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+			AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, table.SchemaName, table.TableName)
+}
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// SampleValues для больших таблиц читает случайные страницы через
+// TABLESAMPLE SYSTEM, чтобы не сканировать таблицу и не брать только
+// первые вставленные строки. Если выборка пуста, читаются первые строки.
+func (d postgresDialect) SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error) {
+	col := d.QuoteIdent(column.ColumnName)
+	from := d.QuoteIdent(table.SchemaName) + "." + d.QuoteIdent(table.TableName)
+
+	if table.TableType == "BASE TABLE" {
+		var estimate float64
+		err := db.QueryRowContext(ctx, `
+			SELECT c.reltuples
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2
+		`, table.SchemaName, table.TableName).Scan(&estimate)
+		if err == nil && estimate > postgresSampleThreshold {
+			// Берем с запасом: часть значений может оказаться NULL или пустой
+			percent := min(100, max(0.01, float64(limit)*10*100/estimate))
+			query := fmt.Sprintf(`
+				SELECT %s::text
+				FROM %s TABLESAMPLE SYSTEM (%g)
+				WHERE %s IS NOT NULL AND %s::text <> ''
+				LIMIT %d
+			`, col, from, percent, col, col, limit)
+
+			values, err := queryStrings(ctx, db, query)
+			if err == nil && len(values) > 0 {
+				return values, nil
+			}
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s::text
+		FROM %s
+		WHERE %s IS NOT NULL AND %s::text <> ''
+		LIMIT %d
+	`, col, from, col, col, limit)
+
+	values, err := queryStrings(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("запрос значений: %v", err)
+	}
+	return values, nil
+}
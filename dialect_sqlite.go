@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteExtensions — расширения файлов, по которым --database без явного
+// --driver распознается как файл SQLite.
+var sqliteExtensions = []string{".db", ".sqlite", ".sqlite3", ".db3"}
+
+// sqliteDialect читает локальный файл SQLite; --database задает путь к нему.
+// Сервер, порт и учетные данные не используются.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string            { return "sqlite" }
+func (sqliteDialect) DefaultPort() string     { return "" }
+func (sqliteDialect) DefaultDatabase() string { return "" }
+
+// Local отличает файловую СУБД от серверных: для нее не запрашиваются
+// сервер, порт и учетные данные.
+func (sqliteDialect) Local() bool { return true }
+
+func (sqliteDialect) Validate(opts *Options) error {
+	if opts.Auth.Mode != authSQL {
+		return errors.New("для sqlite аутентификация не используется")
+	}
+	if opts.TLS != (TLSOptions{}) {
+		return errors.New("для sqlite параметры шифрования соединения не применяются")
+	}
+	if opts.AllDatabases {
+		return errors.New("для sqlite укажите путь к файлу в --database вместо --all-databases")
+	}
+	if opts.Database != "" {
+		info, err := os.Stat(opts.Database)
+		if err != nil {
+			return fmt.Errorf("файл БД sqlite: %v", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s — каталог, а не файл БД sqlite", opts.Database)
+		}
+	}
+	return nil
+}
+
+func (sqliteDialect) DriverName(*Options) string { return "sqlite" }
+
+// DSN открывает файл только на чтение, чтобы проверка не меняла его и не
+// создавала пустую БД при опечатке в пути.
+func (sqliteDialect) DSN(opts *Options, database string) string {
+	query := url.Values{}
+	query.Set("mode", "ro")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "query_only(1)")
+
+	u := &url.URL{Scheme: "file", Opaque: (&url.URL{Path: filepath.ToSlash(database)}).EscapedPath()}
+	return u.String() + "?" + query.Encode()
+}
+
+func (sqliteDialect) ConnectionSecurity(context.Context, *sql.DB, *Options) (string, error) {
+	return "локальный файл", nil
+}
+
+func (sqliteDialect) ListDatabases(context.Context, *sql.DB) ([]string, error) {
+	return nil, errors.New("для sqlite список БД не поддерживается")
+}
+
+func (sqliteDialect) ListObjects(ctx context.Context, db *sql.DB) ([]TableInfo, error) {
+	return queryTables(ctx, db, `
+		SELECT 'main', name, UPPER(type)
+		FROM sqlite_master
+		WHERE type IN ('table', 'view')
+			AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		ORDER BY name
+	`)
+}
+
+// ListColumns использует табличную форму PRAGMA table_info; у колонок без
+// объявленного типа DataType пуст.
+func (sqliteDialect) ListColumns(ctx context.Context, db *sql.DB, table TableInfo) ([]ColumnInfo, error) {
+	return queryColumns(ctx, db, `
+		SELECT name, type
+		FROM pragma_table_info(?, ?)
+		ORDER BY cid
+	`, table.TableName, table.SchemaName)
+}
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d sqliteDialect) SampleValues(ctx context.Context, db *sql.DB, table TableInfo, column ColumnInfo, limit int) ([]string, error) {
+	col := d.QuoteIdent(column.ColumnName)
+	from := d.QuoteIdent(table.SchemaName) + "." + d.QuoteIdent(table.TableName)
+
+	query := fmt.Sprintf(`
+		SELECT CAST(%s AS TEXT)
+		FROM %s
+		WHERE %s IS NOT NULL AND CAST(%s AS TEXT) <> ''
+		LIMIT %d
+	`, col, from, col, col, limit)
+
+	values, err := queryStrings(ctx, db, query)
+	if err != nil {
+		return nil, fmt.Errorf("запрос значений: %v", err)
+	}
+	return values, nil
+}
+
+// isSQLiteFile сообщает, похож ли путь на файл SQLite по расширению.
+func isSQLiteFile(path string) bool {
+	return contains(sqliteExtensions, strings.ToLower(filepath.Ext(path)))
+}
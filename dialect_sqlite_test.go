@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSQLiteScan проверяет весь путь анализа БД на файле SQLite: список
+// таблиц, выборку значений, классификацию колонок и запись отчета.
+func TestSQLiteScan(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "app.db")
+	createTestSQLite(t, dbPath, `
+		CREATE TABLE employees (
+			id INTEGER PRIMARY KEY,
+			full_name TEXT,
+			email TEXT,
+			snils TEXT,
+			c1 TEXT,
+			note TEXT
+		);
+		INSERT INTO employees (full_name, email, snils, c1, note) VALUES
+			('Иванов Иван Иванович', 'ivanov@example.com', '112-233-445 95', '500100732259', 'отпуск'),
+			('Петрова Анна Сергеевна', 'petrova@example.com', '112-233-445 95', '500100732259', 'больничный'),
+			('Сидоров Петр Алексеевич', 'sidorov@example.com', '112-233-445 95', '500100732259', 'командировка'),
+			('Кузнецова Мария Ивановна', 'kuznetsova@example.com', '112-233-445 95', '500100732259', 'отпуск'),
+			('Смирнов Алексей Петрович', 'smirnov@example.com', '112-233-445 95', '500100732259', 'обучение');
+		CREATE VIEW v_emails AS SELECT email FROM employees;`)
+
+	output := filepath.Join(dir, "report.csv")
+	opts, err := parseOptions([]string{"--database", dbPath, "--output", output, "--non-interactive"})
+	if err != nil {
+		t.Fatalf("parseOptions: %v", err)
+	}
+	if opts.Driver != "sqlite" {
+		t.Fatalf("driver = %q, ожидался sqlite по расширению файла", opts.Driver)
+	}
+
+	db, err := connectToDB(opts, opts.Database)
+	if err != nil {
+		t.Fatalf("connectToDB: %v", err)
+	}
+	defer db.Close()
+
+	tables, err := getTablesAndViews(db, opts.dialect, opts.ListTimeout)
+	if err != nil {
+		t.Fatalf("getTablesAndViews: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("найдено объектов: %d, ожидались таблица и представление", len(tables))
+	}
+
+	resultsChan := make(chan PDNResult, 100)
+	saved := make(chan error)
+	go func() {
+		saved <- saveResultsToCSVBatches(output, opts.BatchSize, true, resultsChan)
+	}()
+	analyzeTablesWithBatches(db, opts, opts.Database, tables, resultsChan)
+	close(resultsChan)
+	if err := <-saved; err != nil {
+		t.Fatalf("saveResultsToCSVBatches: %v", err)
+	}
+
+	records := readTestCSV(t, output)
+	header := records[0]
+	col := make(map[string]int)
+	for i, name := range header {
+		col[name] = i
+	}
+	for _, name := range []string{"Таблица/Представление", "Колонка", "ПДн (Да\\Нет)", "Тип ПДн", "Проверка значений", "Уверенность", "Защита соединения"} {
+		if _, ok := col[name]; !ok {
+			t.Fatalf("в отчете нет колонки %q: %v", name, header)
+		}
+	}
+
+	type key struct{ table, column, pdnType string }
+	verdicts := make(map[key]string)
+	validation := make(map[key]string)
+	for _, r := range records[1:] {
+		k := key{r[col["Таблица/Представление"]], r[col["Колонка"]], r[col["Тип ПДн"]]}
+		verdicts[k] = r[col["ПДн (Да\\Нет)"]]
+		validation[k] = r[col["Проверка значений"]]
+	}
+
+	for _, tc := range []struct {
+		key
+		verdict string
+	}{
+		{key{"employees", "full_name", "ФИО"}, "Да"},
+		{key{"employees", "email", "Email"}, "Да"},
+		{key{"employees", "snils", "СНИЛС"}, "Да"},
+		{key{"employees", "c1", "ИНН физлица"}, "Да"},
+		{key{"employees", "note", "Нет"}, "Нет"},
+		{key{"v_emails", "email", "Email"}, "Да"},
+	} {
+		got, ok := verdicts[tc.key]
+		if !ok {
+			t.Errorf("%v: нет строки в отчете", tc.key)
+			continue
+		}
+		if got != tc.verdict {
+			t.Errorf("%v: ПДн = %q, ожидалось %q", tc.key, got, tc.verdict)
+		}
+	}
+
+	if got := validation[key{"employees", "snils", "СНИЛС"}]; got != validators["snils"].passed {
+		t.Errorf("проверка СНИЛС = %q", got)
+	}
+}
+
+func createTestSQLite(t *testing.T, path, script string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(script); err != nil {
+		t.Fatalf("создание БД: %v", err)
+	}
+}
+
+func readTestCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("чтение отчета: %v", err)
+	}
+	if len(records) == 0 {
+		t.Fatal("отчет пуст")
+	}
+	return records
+}
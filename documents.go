@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// documentSchema — поля, найденные в выборке документов или строк файла, в
+// порядке появления, с типом и примерами значений.
+type documentSchema struct {
+	columns []ColumnInfo
+	index   map[string]int
+	values  map[string][]string
+	limit   int
+	// declared — поля из встроенной схемы файла, их тип не выводится
+	declared map[string]bool
+}
+
+func newDocumentSchema(limit int) *documentSchema {
+	return &documentSchema{
+		index:    make(map[string]int),
+		values:   make(map[string][]string),
+		limit:    limit,
+		declared: make(map[string]bool),
+	}
+}
+
+// declare добавляет поле с типом из схемы файла (Parquet, Avro).
+func (s *documentSchema) declare(path, dataType string) {
+	s.add(path, dataType, "")
+	s.declared[path] = true
+}
+
+// add учитывает значение поля; поле с разными типами в разных документах
+// получает тип mixed.
+func (s *documentSchema) add(path, dataType, value string) {
+	i, ok := s.index[path]
+	if !ok {
+		s.index[path] = len(s.columns)
+		s.columns = append(s.columns, ColumnInfo{ColumnName: path, DataType: dataType})
+	} else if dataType != "null" && !s.declared[path] {
+		switch s.columns[i].DataType {
+		case dataType, "mixed":
+		case "null":
+			s.columns[i].DataType = dataType
+		default:
+			s.columns[i].DataType = "mixed"
+		}
+	}
+
+	if value != "" && len(s.values[path]) < s.limit {
+		s.values[path] = append(s.values[path], value)
+	}
+}
+
+// full сообщает, что для всех найденных полей уже набрано limit значений;
+// пока полей нет, выборка не считается полной.
+func (s *documentSchema) full() bool {
+	if len(s.columns) == 0 {
+		return false
+	}
+	for _, col := range s.columns {
+		if len(s.values[col.ColumnName]) < s.limit {
+			return false
+		}
+	}
+	return true
+}
+
+// flatten раскладывает документ на пути полей: вложенные документы
+// разделяются точкой, элементы массивов обозначаются [].
+func (s *documentSchema) flatten(path string, v any) {
+	switch x := v.(type) {
+	case bson.D:
+		for _, e := range x {
+			s.flatten(joinFieldPath(path, e.Key), e.Value)
+		}
+	case bson.M:
+		for key, value := range x {
+			s.flatten(joinFieldPath(path, key), value)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s.flatten(joinFieldPath(path, key), x[key])
+		}
+	case bson.A:
+		for _, item := range x {
+			s.flatten(path+"[]", item)
+		}
+	case []any:
+		for _, item := range x {
+			s.flatten(path+"[]", item)
+		}
+	default:
+		dataType, value := scalarValue(x)
+		s.add(path, dataType, value)
+	}
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// scalarValue возвращает имя типа (в терминах BSON) и строковое
+// представление значения. Двоичные данные не выбираются.
+func scalarValue(v any) (string, string) {
+	switch x := v.(type) {
+	case nil:
+		return "null", ""
+	case string:
+		return "string", x
+	case int, int8, int16, int32, uint8, uint16:
+		return "int", fmt.Sprint(x)
+	case int64, uint32, uint64:
+		return "long", fmt.Sprint(x)
+	case float32, float64:
+		return "double", fmt.Sprint(x)
+	case time.Time:
+		return "date", x.UTC().Format(time.DateTime)
+	case []byte:
+		return "binData", ""
+	case bool:
+		return "bool", fmt.Sprint(x)
+	case bson.DateTime:
+		return "date", x.Time().UTC().Format(time.DateTime)
+	case bson.ObjectID:
+		return "objectId", x.Hex()
+	case bson.Decimal128:
+		return "decimal", x.String()
+	case bson.Binary:
+		return "binData", ""
+	}
+	return fmt.Sprintf("%T", v), fmt.Sprint(v)
+}
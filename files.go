@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// fileRowLimit ограничивает число читаемых строк файла: значения берутся
+// из начала файла, как и первые строки таблицы в СУБД.
+const fileRowLimit = 10000
+
+// fileTable — таблица, прочитанная из файла: сам файл, лист книги Excel или
+// таблица из SQL-дампа.
+type fileTable struct {
+	name   string
+	schema *documentSchema
+}
+
+// fileTableReader читает таблицы из открытого файла. Для файла с одной
+// таблицей имя таблицы пустое, и вместо него используется имя файла.
+type fileTableReader func(f dataFile, size int64, sampleSize int) ([]fileTable, error)
+
+var fileReaders = map[string]fileTableReader{
+	".csv":     readDelimitedFile,
+	".tsv":     readDelimitedFile,
+	".tab":     readDelimitedFile,
+	".jsonl":   readJSONLinesFile,
+	".ndjson":  readJSONLinesFile,
+	".xlsx":    readExcelFile,
+	".xlsm":    readExcelFile,
+	".parquet": readParquetFile,
+	".avro":    readAvroFile,
+	".sql":     readSQLDumpFile,
+}
+
+// scanFiles проверяет выгрузки в каталоге --scan-files, отдельном файле
+// или бакете S3. Каждый файл или лист считается таблицей, заголовки —
+// колонками; в отчете источник записывается вместо БД, путь к файлу —
+// вместо схемы.
+func scanFiles(opts *Options, resultsChan chan<- PDNResult) error {
+	root := opts.ScanFiles
+	source, err := openFileSource(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ListTimeout)
+	files, err := source.List(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nНайдено %d файлов для анализа в %s\n", len(files), root)
+
+	for i, file := range files {
+		ext := strings.ToLower(path.Ext(file.Name))
+		tableType := strings.ToUpper(strings.TrimPrefix(ext, "."))
+		baseName := path.Base(file.Name)
+
+		fmt.Printf("\n[%d/%d] Анализ файла %s...\n", i+1, len(files), file.Name)
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.TableTimeout)
+		tables, err := readDataFile(ctx, source, file, opts.SampleSize)
+		cancel()
+		if err != nil {
+			log.Printf("⚠ Ошибка чтения файла: %v - пропускаем\n", err)
+			resultsChan <- PDNResult{
+				DatabaseName: root,
+				SchemaName:   file.Name,
+				TableName:    baseName,
+				TableType:    tableType,
+				ColumnName:   "ALL_COLUMNS",
+				FoundIn:      "error",
+				SampleValue:  "N/A",
+				Pattern:      fmt.Sprintf("Ошибка чтения файла: %v", err),
+				PDNType:      "Не обработано",
+			}
+			continue
+		}
+
+		for _, ft := range tables {
+			table := TableInfo{SchemaName: file.Name, TableName: ft.name, TableType: tableType}
+			if ft.name == "" {
+				table.TableName = baseName
+			}
+			if len(filterTables([]TableInfo{table}, opts)) == 0 {
+				continue
+			}
+
+			if ft.name != "" {
+				fmt.Printf("  Таблица %s\n", ft.name)
+			}
+			fmt.Printf("  Найдено %d колонок\n", len(ft.schema.columns))
+			for _, col := range ft.schema.columns {
+				fmt.Printf("  - %s (%s)\n", col.ColumnName, col.DataType)
+			}
+
+			var tableResults []PDNResult
+			for _, col := range ft.schema.columns {
+				tableResults = append(tableResults, classifyColumn(opts.rules, root, table, col, ft.schema.values[col.ColumnName])...)
+			}
+			finishTable(opts.rules, tableResults, resultsChan)
+		}
+	}
+
+	return nil
+}
+
+func readDataFile(ctx context.Context, source fileSource, file sourceFile, sampleSize int) ([]fileTable, error) {
+	f, err := source.Open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return fileReaders[strings.ToLower(path.Ext(file.Name))](f, file.Size, sampleSize)
+}
+
+// dataFile — открытый файл выгрузки: локальный файл или объект S3.
+type dataFile interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+}
+
+// sourceFile — файл в источнике: Name — путь относительно корня источника
+// через '/', Key — полный путь или ключ объекта для открытия.
+type sourceFile struct {
+	Name string
+	Key  string
+	Size int64
+}
+
+// fileSource — место хранения выгрузок: локальный каталог или бакет S3.
+type fileSource interface {
+	// List возвращает поддерживаемые файлы в лексическом порядке
+	List(ctx context.Context) ([]sourceFile, error)
+	Open(ctx context.Context, file sourceFile) (dataFile, error)
+}
+
+func openFileSource(opts *Options) (fileSource, error) {
+	if strings.HasPrefix(opts.ScanFiles, "s3://") {
+		return newS3Source(opts)
+	}
+	return localSource{root: opts.ScanFiles}, nil
+}
+
+func isDataFile(name string) bool {
+	_, ok := fileReaders[strings.ToLower(path.Ext(name))]
+	return ok
+}
+
+// localSource — каталог (обходится рекурсивно, скрытые каталоги
+// пропускаются) или отдельный файл.
+type localSource struct {
+	root string
+}
+
+func (s localSource) List(context.Context) ([]sourceFile, error) {
+	info, err := os.Stat(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("каталог с файлами: %v", err)
+	}
+	if !info.IsDir() {
+		if !isDataFile(s.root) {
+			return nil, fmt.Errorf("формат файла %s не поддерживается", s.root)
+		}
+		return []sourceFile{{Name: filepath.Base(s.root), Key: s.root, Size: info.Size()}}, nil
+	}
+
+	var files []sourceFile
+	err = filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("⚠ %s пропущен: %v\n", p, err)
+			if d != nil && d.IsDir() && p != s.root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if p != s.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isDataFile(p) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			log.Printf("⚠ %s пропущен: %v\n", p, err)
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, sourceFile{Name: filepath.ToSlash(rel), Key: p, Size: info.Size()})
+		return nil
+	})
+	return files, err
+}
+
+func (localSource) Open(_ context.Context, file sourceFile) (dataFile, error) {
+	return os.Open(file.Key)
+}
+
+// readDelimitedFile читает CSV/TSV с заголовком в первой строке.
+// Разделитель (',', ';' или табуляция) определяется по заголовку.
+func readDelimitedFile(f dataFile, _ int64, sampleSize int) ([]fileTable, error) {
+	r := textReader(f)
+	head, _ := r.Peek(64 * 1024)
+	comma := detectDelimiter(head)
+
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	schema := newDocumentSchema(sampleSize)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return []fileTable{{schema: schema}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("заголовок: %v", err)
+	}
+	names := headerNames(header)
+	for _, name := range names {
+		schema.add(name, "text", "")
+	}
+
+	for row := 0; row < fileRowLimit && !schema.full(); row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range record {
+			if i < len(names) {
+				schema.add(names[i], "text", strings.TrimSpace(value))
+			}
+		}
+	}
+
+	return []fileTable{{schema: schema}}, nil
+}
+
+// readJSONLinesFile читает по объекту JSON в строке; вложенные объекты и
+// массивы раскладываются на пути полей, как документы MongoDB.
+func readJSONLinesFile(f dataFile, _ int64, sampleSize int) ([]fileTable, error) {
+	scanner := bufio.NewScanner(textReader(f))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	schema := newDocumentSchema(sampleSize)
+	for line, rows := 0, 0; rows < fileRowLimit && scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+			return nil, fmt.Errorf("строка %d: %v", line+1, err)
+		}
+		schema.flatten("", doc)
+		rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return []fileTable{{schema: schema}}, nil
+}
+
+// readExcelFile читает каждый лист книги как отдельную таблицу; заголовок —
+// первая непустая строка листа.
+func readExcelFile(f dataFile, _ int64, sampleSize int) ([]fileTable, error) {
+	book, err := excelize.OpenReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+
+	var tables []fileTable
+	for _, sheet := range book.GetSheetList() {
+		schema, err := readExcelSheet(book, sheet, sampleSize)
+		if err != nil {
+			return nil, fmt.Errorf("лист %s: %v", sheet, err)
+		}
+		tables = append(tables, fileTable{name: sheet, schema: schema})
+	}
+	return tables, nil
+}
+
+func readExcelSheet(book *excelize.File, sheet string, sampleSize int) (*documentSchema, error) {
+	rows, err := book.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schema := newDocumentSchema(sampleSize)
+	var names []string
+	for row := 0; row < fileRowLimit && rows.Next(); row++ {
+		cells, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		if names == nil {
+			if len(cells) == 0 {
+				continue
+			}
+			names = headerNames(cells)
+			for _, name := range names {
+				schema.add(name, "text", "")
+			}
+			continue
+		}
+		for i, value := range cells {
+			if i < len(names) {
+				schema.add(names[i], "text", strings.TrimSpace(value))
+			}
+		}
+		if schema.full() {
+			break
+		}
+	}
+
+	return schema, rows.Error()
+}
+
+// headerNames подставляет имена для пустых заголовков и делает повторяющиеся
+// уникальными.
+func headerNames(header []string) []string {
+	names := make([]string, len(header))
+	seen := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// detectDelimiter выбирает разделитель, чаще всего встречающийся в первой
+// строке; выгрузки из русской локали Excel обычно разделены ';'.
+func detectDelimiter(head []byte) rune {
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	best, count := ',', bytes.Count(head, []byte{','})
+	for _, c := range []rune{';', '\t'} {
+		if n := bytes.Count(head, []byte(string(c))); n > count {
+			best, count = c, n
+		}
+	}
+	return best
+}
+
+// textReader пропускает BOM и перекодирует файл из UTF-16 (по BOM) или из
+// Windows-1251, если начало файла не является корректным UTF-8.
+func textReader(r io.Reader) *bufio.Reader {
+	br := bufio.NewReaderSize(r, 64*1024)
+	head, _ := br.Peek(64 * 1024)
+	if bytes.HasPrefix(head, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+		return br
+	}
+	if bytes.HasPrefix(head, []byte("\xff\xfe")) || bytes.HasPrefix(head, []byte("\xfe\xff")) {
+		// Так по умолчанию сохраняет скрипты SQL Server Management Studio
+		decoder := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+		return bufio.NewReaderSize(decoder.Reader(br), 64*1024)
+	}
+	// Последний символ в буфере может быть обрезан, проверяем до конца строки
+	if i := bytes.LastIndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	if utf8.Valid(head) {
+		return br
+	}
+	return bufio.NewReaderSize(charmap.Windows1251.NewDecoder().Reader(br), 64*1024)
+}
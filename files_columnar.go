@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/parquet-go/parquet-go"
+)
+
+// readParquetFile берет колонки из схемы файла, а значения — из нескольких
+// групп строк, равномерно распределенных по файлу, чтобы выборка не
+// ограничивалась его началом.
+func readParquetFile(f dataFile, size int64, sampleSize int) ([]fileTable, error) {
+	file, err := parquet.OpenFile(f, size)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := newDocumentSchema(sampleSize)
+	declareParquetFields(schema, "", file.Schema())
+
+	groups := sampleRowGroups(file.RowGroups(), sampleSize)
+	quota := (sampleSize + len(groups) - 1) / max(1, len(groups))
+	for i, group := range groups {
+		// Каждая следующая группа добирает свою долю значений
+		schema.limit = min(sampleSize, quota*(i+1))
+		if err := readParquetRowGroup(schema, file.Schema(), group, fileRowLimit/len(groups)); err != nil {
+			return nil, err
+		}
+	}
+	schema.limit = sampleSize
+
+	return []fileTable{{schema: schema}}, nil
+}
+
+// sampleRowGroups выбирает не более n групп строк через равные промежутки.
+func sampleRowGroups(groups []parquet.RowGroup, n int) []parquet.RowGroup {
+	if len(groups) <= n {
+		return groups
+	}
+	sampled := make([]parquet.RowGroup, n)
+	for i := range sampled {
+		sampled[i] = groups[i*len(groups)/n]
+	}
+	return sampled
+}
+
+func readParquetRowGroup(schema *documentSchema, fileSchema *parquet.Schema, group parquet.RowGroup, rowLimit int) error {
+	rows := group.Rows()
+	defer rows.Close()
+
+	buf := make([]parquet.Row, 64)
+	for read := 0; read < rowLimit && !schema.full(); {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			var record any
+			if err := fileSchema.Reconstruct(&record, row); err != nil {
+				return err
+			}
+			schema.flatten("", parquetLogicalValue(fileSchema, record))
+		}
+		read += n
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parquetLogicalValue переводит даты и отметки времени в time.Time:
+// Reconstruct возвращает для них число дней или единиц времени от
+// 1970-01-01, и без перевода они не узнавались бы как даты.
+func parquetLogicalValue(node parquet.Node, v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for _, field := range node.Fields() {
+			if value, ok := x[field.Name()]; ok {
+				x[field.Name()] = parquetLogicalValue(field, value)
+			}
+		}
+		return x
+	case []any:
+		element := node
+		if lt := node.Type().LogicalType(); lt != nil && lt.List != nil && len(node.Fields()) == 1 {
+			element = node.Fields()[0]
+			if len(element.Fields()) == 1 {
+				element = element.Fields()[0]
+			}
+		}
+		for i, item := range x {
+			x[i] = parquetLogicalValue(element, item)
+		}
+		return x
+	}
+
+	lt := node.Type().LogicalType()
+	switch {
+	case lt == nil:
+	case lt.Date != nil:
+		if days, ok := v.(int32); ok {
+			return time.Unix(int64(days)*24*60*60, 0).UTC()
+		}
+	case lt.Timestamp != nil:
+		if n, ok := v.(int64); ok {
+			switch unit := lt.Timestamp.Unit; {
+			case unit.Millis != nil:
+				return time.UnixMilli(n).UTC()
+			case unit.Micros != nil:
+				return time.UnixMicro(n).UTC()
+			case unit.Nanos != nil:
+				return time.Unix(0, n).UTC()
+			}
+		}
+	}
+	return v
+}
+
+// declareParquetFields раскладывает схему Parquet на пути полей так же, как
+// flatten раскладывает прочитанные строки: списки (LIST и повторяющиеся
+// поля) обозначаются [].
+func declareParquetFields(schema *documentSchema, path string, node parquet.Node) {
+	for _, field := range node.Fields() {
+		fieldPath := joinFieldPath(path, field.Name())
+		if field.Repeated() {
+			fieldPath += "[]"
+		}
+
+		if lt := field.Type().LogicalType(); lt != nil && lt.List != nil && len(field.Fields()) == 1 {
+			// LIST: группа с повторяющимся элементом list.element
+			element := field.Fields()[0]
+			if len(element.Fields()) == 1 {
+				element = element.Fields()[0]
+			}
+			fieldPath += "[]"
+			if element.Leaf() {
+				schema.declare(fieldPath, parquetTypeName(element))
+			} else {
+				declareParquetFields(schema, fieldPath, element)
+			}
+			continue
+		}
+
+		if field.Leaf() {
+			schema.declare(fieldPath, parquetTypeName(field))
+		} else {
+			declareParquetFields(schema, fieldPath, field)
+		}
+	}
+}
+
+// parquetTypeName возвращает логический тип колонки, если он задан, иначе
+// физический.
+func parquetTypeName(node parquet.Node) string {
+	if lt := node.Type().LogicalType(); lt != nil {
+		return strings.ToLower(lt.String())
+	}
+	return strings.ToLower(node.Type().String())
+}
+
+// readAvroFile читает контейнер Avro (OCF): колонки берутся из встроенной
+// схемы записи, значения — из первых записей файла.
+func readAvroFile(f dataFile, _ int64, sampleSize int) ([]fileTable, error) {
+	dec, err := ocf.NewDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := newDocumentSchema(sampleSize)
+	record, ok := dec.Schema().(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("схема файла — %s, ожидается record", dec.Schema().Type())
+	}
+	declareAvroFields(schema, "", record)
+
+	for row := 0; row < fileRowLimit && !schema.full() && dec.HasNext(); row++ {
+		var value map[string]any
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("запись %d: %v", row+1, err)
+		}
+		schema.flatten("", value)
+	}
+	if err := dec.Error(); err != nil {
+		return nil, err
+	}
+
+	return []fileTable{{schema: schema}}, nil
+}
+
+func declareAvroFields(schema *documentSchema, path string, record *avro.RecordSchema) {
+	for _, field := range record.Fields() {
+		declareAvroType(schema, joinFieldPath(path, field.Name()), field.Type())
+	}
+}
+
+func declareAvroType(schema *documentSchema, path string, s avro.Schema) {
+	switch t := s.(type) {
+	case *avro.RecordSchema:
+		declareAvroFields(schema, path, t)
+	case *avro.ArraySchema:
+		declareAvroType(schema, path+"[]", t.Items())
+	case *avro.UnionSchema:
+		// ["null", T] — обычное необязательное поле типа T
+		var types []avro.Schema
+		for _, u := range t.Types() {
+			if u.Type() != avro.Null {
+				types = append(types, u)
+			}
+		}
+		if len(types) == 1 {
+			declareAvroType(schema, path, types[0])
+			return
+		}
+		schema.declare(path, "union")
+	default:
+		schema.declare(path, avroTypeName(s))
+	}
+}
+
+func avroTypeName(s avro.Schema) string {
+	if ls, ok := s.(avro.LogicalTypeSchema); ok && ls.Logical() != nil {
+		return string(ls.Logical().Type())
+	}
+	return string(s.Type())
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/parquet-go/parquet-go"
+)
+
+type testParquetPhone struct {
+	Number string `parquet:"number"`
+	Kind   string `parquet:"kind,optional"`
+}
+
+type testParquetContacts struct {
+	Email  string             `parquet:"email,optional"`
+	Phones []testParquetPhone `parquet:"phones,list"`
+}
+
+type testParquetRow struct {
+	ID       int64               `parquet:"id"`
+	FullName string              `parquet:"full_name"`
+	Born     int32               `parquet:"born,date"`
+	Created  int64               `parquet:"created,timestamp(millisecond)"`
+	Tags     []string            `parquet:"tags"`
+	Contacts testParquetContacts `parquet:"contacts"`
+}
+
+func TestReadParquetFile(t *testing.T) {
+	rows := []testParquetRow{
+		{
+			ID: 1, FullName: "Иванов Иван Иванович", Born: testDays(1985, 3, 12),
+			Created: time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC).UnixMilli(),
+			Tags:    []string{"vip", "new"},
+			Contacts: testParquetContacts{
+				Email:  "ivanov@example.com",
+				Phones: []testParquetPhone{{Number: "+79161234567", Kind: "mobile"}, {Number: "+74951234567"}},
+			},
+		},
+		{
+			ID: 2, FullName: "Петрова Анна", Born: testDays(1990, 7, 1),
+			Created: time.Date(2024, 6, 15, 8, 0, 0, 0, time.UTC).UnixMilli(),
+		},
+	}
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	f := writeTestFile(t, "clients.parquet", data)
+
+	tables, err := readParquetFile(f, int64(len(data)), 10)
+	if err != nil {
+		t.Fatalf("readParquetFile: %v", err)
+	}
+	checkFileTable(t, tables[0],
+		[]ColumnInfo{
+			{ColumnName: "id", DataType: "int(64,true)"},
+			{ColumnName: "full_name", DataType: "string"},
+			{ColumnName: "born", DataType: "date"},
+			{ColumnName: "created", DataType: "timestamp(isadjustedtoutc=true,unit=millis)"},
+			{ColumnName: "tags[]", DataType: "string"},
+			{ColumnName: "contacts.email", DataType: "string"},
+			{ColumnName: "contacts.phones[].number", DataType: "string"},
+			{ColumnName: "contacts.phones[].kind", DataType: "string"},
+		},
+		map[string][]string{
+			"id":                       {"1", "2"},
+			"full_name":                {"Иванов Иван Иванович", "Петрова Анна"},
+			"born":                     {"1985-03-12 00:00:00", "1990-07-01 00:00:00"},
+			"created":                  {"2024-05-01 10:30:00", "2024-06-15 08:00:00"},
+			"tags[]":                   {"vip", "new"},
+			"contacts.email":           {"ivanov@example.com"},
+			"contacts.phones[].number": {"+79161234567", "+74951234567"},
+			"contacts.phones[].kind":   {"mobile"},
+		})
+}
+
+// TestReadParquetRowGroups проверяет, что значения берутся из групп строк,
+// равномерно распределенных по файлу, а не только из начала.
+func TestReadParquetRowGroups(t *testing.T) {
+	type row struct {
+		ID string `parquet:"id"`
+	}
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[row](&buf, parquet.MaxRowsPerRowGroup(10))
+	for i := 0; i < 100; i++ {
+		if _, err := w.Write([]row{{ID: strconv.Itoa(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	f := writeTestFile(t, "events.parquet", data)
+
+	tests := []struct {
+		sampleSize int
+		values     []string
+	}{
+		{3, []string{"0", "30", "60"}},
+		{4, []string{"0", "20", "50", "70"}},
+		{6, []string{"0", "10", "30", "50", "60", "80"}},
+		{12, []string{"0", "1", "10", "11", "20", "21", "30", "31", "40", "41", "50", "51"}},
+	}
+	for _, tt := range tests {
+		tables, err := readParquetFile(f, int64(len(data)), tt.sampleSize)
+		if err != nil {
+			t.Fatalf("readParquetFile: %v", err)
+		}
+		if got := tables[0].schema.values["id"]; !reflect.DeepEqual(got, tt.values) {
+			t.Errorf("выборка %d: %q, ожидалось %q", tt.sampleSize, got, tt.values)
+		}
+	}
+}
+
+func TestReadAvroFile(t *testing.T) {
+	const schema = `{
+		"type": "record", "name": "Client",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "full_name", "type": ["null", "string"]},
+			{"name": "born", "type": {"type": "int", "logicalType": "date"}},
+			{"name": "code", "type": ["null", "string", "long"]},
+			{"name": "address", "type": {
+				"type": "record", "name": "Address",
+				"fields": [
+					{"name": "city", "type": "string"},
+					{"name": "phones", "type": {"type": "array", "items": "string"}}
+				]
+			}}
+		]
+	}`
+	records := []map[string]any{
+		{
+			"id": int64(1), "full_name": map[string]any{"string": "Иванов Иван"},
+			"born": time.Date(1985, 3, 12, 0, 0, 0, 0, time.UTC), "code": map[string]any{"string": "A-1"},
+			"address": map[string]any{"city": "Москва", "phones": []any{"+79161234567", "+74951234567"}},
+		},
+		{
+			"id": int64(2), "full_name": nil,
+			"born": time.Date(1990, 7, 1, 0, 0, 0, 0, time.UTC), "code": map[string]any{"long": int64(42)},
+			"address": map[string]any{"city": "Тверь", "phones": []any{}},
+		},
+	}
+	var buf bytes.Buffer
+	enc, err := ocf.NewEncoder(schema, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	f := writeTestFile(t, "clients.avro", data)
+
+	tables, err := readAvroFile(f, int64(len(data)), 10)
+	if err != nil {
+		t.Fatalf("readAvroFile: %v", err)
+	}
+	checkFileTable(t, tables[0],
+		[]ColumnInfo{
+			{ColumnName: "id", DataType: "long"},
+			{ColumnName: "full_name", DataType: "string"},
+			{ColumnName: "born", DataType: "date"},
+			{ColumnName: "code", DataType: "union"},
+			{ColumnName: "address.city", DataType: "string"},
+			{ColumnName: "address.phones[]", DataType: "string"},
+		},
+		map[string][]string{
+			"id":               {"1", "2"},
+			"full_name":        {"Иванов Иван"},
+			"born":             {"1985-03-12 00:00:00", "1990-07-01 00:00:00"},
+			"code":             {"A-1", "42"},
+			"address.city":     {"Москва", "Тверь"},
+			"address.phones[]": {"+79161234567", "+74951234567"},
+		})
+}
+
+// testDays возвращает дату в днях от 1970-01-01, как ее хранит Parquet.
+func testDays(year int, month time.Month, day int) int32 {
+	return int32(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
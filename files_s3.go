@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// defaultS3Endpoint используется, если --s3-endpoint не указан.
+const defaultS3Endpoint = "https://s3.amazonaws.com"
+
+// s3Source — бакет S3-совместимого хранилища (MinIO, Ceph, AWS S3), заданный
+// как --scan-files s3://bucket/prefix. Ключи доступа берутся из
+// AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY (или MINIO_ROOT_USER/
+// MINIO_ROOT_PASSWORD), без них запросы выполняются анонимно.
+type s3Source struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3Source(opts *Options) (*s3Source, error) {
+	u, err := url.Parse(opts.ScanFiles)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("некорректный адрес %q, ожидается s3://bucket/prefix", opts.ScanFiles)
+	}
+
+	endpointURL := opts.S3Endpoint
+	if endpointURL == "" {
+		endpointURL = defaultS3Endpoint
+	}
+	endpoint, err := url.Parse(endpointURL)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("некорректный адрес --s3-endpoint %q, ожидается http(s)://host:port", endpointURL)
+	}
+	secure := endpoint.Scheme == "https"
+
+	transport, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return nil, err
+	}
+	if secure {
+		if opts.TLS.TrustServerCert {
+			transport.TLSClientConfig.InsecureSkipVerify = true
+		}
+		if opts.TLS.CAFile != "" {
+			pem, err := os.ReadFile(opts.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("чтение --ca-file: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("в %s не найдено сертификатов", opts.TLS.CAFile)
+			}
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	} else if opts.TLS.RequireEncryption {
+		return nil, errors.New("указан --require-encryption, а --s3-endpoint использует http")
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+		}),
+		Secure:       secure,
+		Transport:    transport,
+		Region:       os.Getenv("AWS_REGION"),
+		BucketLookup: minio.BucketLookupAuto,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("подключение к S3: %v", err)
+	}
+
+	return &s3Source{
+		client: client,
+		bucket: u.Host,
+		prefix: strings.TrimPrefix(u.Path, "/"),
+	}, nil
+}
+
+func (s *s3Source) List(ctx context.Context) ([]sourceFile, error) {
+	// Имена файлов считаются от последнего '/' в префиксе
+	dir := s.prefix[:strings.LastIndex(s.prefix, "/")+1]
+
+	var files []sourceFile
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.prefix, Recursive: true})
+	for obj := range objects {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("список объектов s3://%s/%s: %v", s.bucket, s.prefix, obj.Err)
+		}
+		if !isDataFile(obj.Key) {
+			continue
+		}
+
+		files = append(files, sourceFile{
+			Name: strings.TrimPrefix(obj.Key, dir),
+			Key:  obj.Key,
+			Size: obj.Size,
+		})
+	}
+	return files, nil
+}
+
+// Open возвращает объект, который читает данные диапазонами по запросу,
+// поэтому для Parquet скачиваются только метаданные и выбранные группы строк.
+func (s *s3Source) Open(ctx context.Context, file sourceFile) (dataFile, error) {
+	return s.client.GetObject(ctx, s.bucket, file.Key, minio.GetObjectOptions{})
+}
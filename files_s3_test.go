@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// fakeS3 — бакет S3 в памяти: список объектов (ListObjectsV2) и чтение
+// объекта с диапазонами байтов.
+type fakeS3 struct {
+	bucket  string
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	if key != "" {
+		data, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, key, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), bytes.NewReader(data))
+		return
+	}
+
+	type object struct {
+		Key          string
+		Size         int
+		LastModified string
+		ETag         string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []object
+	}{Name: s.bucket, Prefix: r.URL.Query().Get("prefix"), MaxKeys: 1000}
+	for key, data := range s.objects {
+		if strings.HasPrefix(key, result.Prefix) {
+			result.Contents = append(result.Contents, object{key, len(data), "2024-01-01T00:00:00.000Z", `"etag"`})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func newTestS3Source(t *testing.T, objects map[string][]byte, scanFiles string) *s3Source {
+	t.Helper()
+	server := httptest.NewServer(&fakeS3{bucket: "lake", objects: objects})
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	source, err := newS3Source(&Options{ScanFiles: scanFiles, S3Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("newS3Source: %v", err)
+	}
+	return source
+}
+
+func TestS3List(t *testing.T) {
+	objects := map[string][]byte{
+		"raw/crm/clients.csv":          []byte("id\n1\n"),
+		"raw/crm/2024/orders.PARQUET":  nil,
+		"raw/crm/readme.txt":           nil,
+		"raw/crm/photos/ivanov.jpg":    nil,
+		"raw/crm.csv":                  nil,
+		"raw/hr/staff.xlsx":            nil,
+		"backup/crm_2024-01-01.sql":    nil,
+		"backup/crm_2024-01-01.sql.gz": nil,
+	}
+	tests := []struct {
+		scanFiles string
+		names     []string
+		keys      []string
+	}{
+		{
+			scanFiles: "s3://lake/raw/crm/",
+			names:     []string{"2024/orders.PARQUET", "clients.csv"},
+			keys:      []string{"raw/crm/2024/orders.PARQUET", "raw/crm/clients.csv"},
+		},
+		{
+			// Префикс — не обязательно каталог: имена считаются от последнего /
+			scanFiles: "s3://lake/raw/cr",
+			names:     []string{"crm.csv", "crm/2024/orders.PARQUET", "crm/clients.csv"},
+			keys:      []string{"raw/crm.csv", "raw/crm/2024/orders.PARQUET", "raw/crm/clients.csv"},
+		},
+		{
+			scanFiles: "s3://lake",
+			names: []string{
+				"backup/crm_2024-01-01.sql", "raw/crm.csv", "raw/crm/2024/orders.PARQUET",
+				"raw/crm/clients.csv", "raw/hr/staff.xlsx",
+			},
+			keys: []string{
+				"backup/crm_2024-01-01.sql", "raw/crm.csv", "raw/crm/2024/orders.PARQUET",
+				"raw/crm/clients.csv", "raw/hr/staff.xlsx",
+			},
+		},
+		{
+			scanFiles: "s3://lake/archive/",
+		},
+	}
+	for _, tt := range tests {
+		source := newTestS3Source(t, objects, tt.scanFiles)
+		files, err := source.List(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.scanFiles, err)
+		}
+		var names, keys []string
+		for _, f := range files {
+			names = append(names, f.Name)
+			keys = append(keys, f.Key)
+		}
+		if !reflect.DeepEqual(names, tt.names) || !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%s: имена %q, ключи %q; ожидались %q, %q", tt.scanFiles, names, keys, tt.names, tt.keys)
+		}
+	}
+}
+
+// TestS3ReadParquet читает Parquet из бакета: объект открывается с чтением
+// диапазонами байтов, как для файла на диске.
+func TestS3ReadParquet(t *testing.T) {
+	type row struct {
+		Email string `parquet:"email"`
+	}
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, []row{{"ivanov@example.com"}, {"petrova@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	source := newTestS3Source(t, map[string][]byte{"raw/users.parquet": buf.Bytes()}, "s3://lake/raw/")
+
+	files, err := source.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Size != int64(buf.Len()) {
+		t.Fatalf("список объектов: %+v", files)
+	}
+	tables, err := readDataFile(context.Background(), source, files[0], 10)
+	if err != nil {
+		t.Fatalf("readDataFile: %v", err)
+	}
+	want := []string{"ivanov@example.com", "petrova@example.com"}
+	if got := tables[0].schema.values["email"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("значения %q, ожидались %q", got, want)
+	}
+}
+
+func TestNewS3SourceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"нет бакета", Options{ScanFiles: "s3:///raw"}},
+		{"схема адреса хранилища", Options{ScanFiles: "s3://lake", S3Endpoint: "ftp://minio:9000"}},
+		{"адрес хранилища без хоста", Options{ScanFiles: "s3://lake", S3Endpoint: "minio:9000"}},
+		{"http при --require-encryption", Options{
+			ScanFiles: "s3://lake", S3Endpoint: "http://minio:9000",
+			TLS: TLSOptions{RequireEncryption: true},
+		}},
+		{"нет --ca-file", Options{
+			ScanFiles: "s3://lake", S3Endpoint: "https://minio:9000",
+			TLS: TLSOptions{CAFile: "/nonexistent/ca.pem"},
+		}},
+	}
+	for _, tt := range tests {
+		if _, err := newS3Source(&tt.opts); err == nil {
+			t.Errorf("%s: ожидалась ошибка", tt.name)
+		}
+	}
+}
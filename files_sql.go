@@ -0,0 +1,758 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// readSQLDumpFile читает SQL-дамп без восстановления в СУБД: таблицы и типы
+// колонок берутся из CREATE TABLE, значения — из INSERT и блоков
+// COPY ... FROM stdin. Поддерживаются дампы mysqldump, pg_dump (в том числе
+// с --inserts) и скрипты SQL Server с разделителем GO. Операторы, которые не
+// удалось разобрать, пропускаются.
+func readSQLDumpFile(f dataFile, _ int64, sampleSize int) ([]fileTable, error) {
+	d := &sqlDump{
+		scanner:    &sqlScanner{r: textReader(f), backslashEscapes: true},
+		tables:     make(map[string]*sqlDumpTable),
+		sampleSize: sampleSize,
+	}
+	for {
+		stmt, err := d.scanner.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := d.apply(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	tables := make([]fileTable, 0, len(d.order))
+	for _, t := range d.order {
+		tables = append(tables, fileTable{name: t.name, schema: t.schema})
+	}
+	return tables, nil
+}
+
+// sqlDump — таблицы, найденные в дампе, в порядке первого упоминания.
+type sqlDump struct {
+	scanner    *sqlScanner
+	tables     map[string]*sqlDumpTable
+	order      []*sqlDumpTable
+	sampleSize int
+}
+
+type sqlDumpTable struct {
+	name string
+	// columns — колонки в порядке CREATE TABLE, для INSERT без списка колонок
+	columns []string
+	schema  *documentSchema
+	rows    int
+}
+
+// table возвращает таблицу по имени; имена сравниваются без учета регистра.
+func (d *sqlDump) table(name string) *sqlDumpTable {
+	key := strings.ToLower(name)
+	t, ok := d.tables[key]
+	if !ok {
+		t = &sqlDumpTable{name: name, schema: newDocumentSchema(d.sampleSize)}
+		d.tables[key] = t
+		d.order = append(d.order, t)
+	}
+	return t
+}
+
+// accepts сообщает, нужны ли таблице еще строки.
+func (t *sqlDumpTable) accepts() bool {
+	return t.rows < fileRowLimit && !t.schema.full()
+}
+
+func (t *sqlDumpTable) addRow(columns []string, values []string, ok []bool) {
+	for i, value := range values {
+		if i >= len(columns) || !ok[i] {
+			continue
+		}
+		t.schema.add(columns[i], "text", strings.TrimSpace(value))
+	}
+	t.rows++
+}
+
+// sqlStatements — операторы, которые разбираются в дампе.
+var sqlStatements = []string{"CREATE", "INSERT", "REPLACE", "COPY", "SET"}
+
+// apply разбирает оператор. Пакет SQL Server между строками GO может
+// содержать несколько операторов без ';', поэтому после каждого разобранного
+// оператора ищется начало следующего.
+func (d *sqlDump) apply(stmt string) error {
+	lex := &sqlLexer{src: stmt, backslashEscapes: d.scanner.backslashEscapes}
+	for tok, ok := lex.next(); ok; tok, ok = lex.nextStatement() {
+		switch strings.ToUpper(tok.text) {
+		case "CREATE":
+			d.createTable(lex)
+		case "INSERT", "REPLACE":
+			d.insert(lex)
+		case "COPY":
+			return d.copy(lex)
+		case "SET":
+			// pg_dump всегда выставляет standard_conforming_strings = on:
+			// обратная косая черта в строках дальше не экранирует символы
+			name, _ := lex.next()
+			value, _ := lex.next()
+			if value.isSymbol("=") || value.isWord("TO") {
+				value, _ = lex.next()
+			}
+			if name.isWord("standard_conforming_strings") {
+				d.scanner.backslashEscapes = !strings.EqualFold(value.text, "on")
+				lex.backslashEscapes = d.scanner.backslashEscapes
+			}
+		}
+	}
+	return nil
+}
+
+// sqlTableModifiers допустимы между CREATE и TABLE.
+var sqlTableModifiers = []string{"OR", "REPLACE", "GLOBAL", "LOCAL", "TEMPORARY", "TEMP", "UNLOGGED"}
+
+// sqlConstraintWords начинают в CREATE TABLE описание ограничения, а не
+// колонки.
+var sqlConstraintWords = []string{
+	"CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "FULLTEXT", "SPATIAL", "EXCLUDE", "LIKE",
+}
+
+// sqlTypeWords продолжают имя типа колонки: character varying,
+// double precision, timestamp with time zone, int unsigned.
+var sqlTypeWords = []string{"VARYING", "PRECISION", "UNSIGNED", "ZEROFILL", "WITH", "WITHOUT", "LOCAL", "TIME", "ZONE"}
+
+func (d *sqlDump) createTable(lex *sqlLexer) {
+	tok, ok := lex.next()
+	for ok && tok.kind == sqlWord && containsFold(sqlTableModifiers, tok.text) {
+		tok, ok = lex.next()
+	}
+	if !ok || !tok.isWord("TABLE") {
+		return
+	}
+	tok, _ = lex.next()
+	if tok.isWord("IF") {
+		lex.next() // NOT
+		lex.next() // EXISTS
+		tok, _ = lex.next()
+	}
+	name, tok, ok := lex.qualifiedName(tok)
+	if !ok || !tok.isSymbol("(") {
+		return
+	}
+
+	t := d.table(name)
+	t.columns = t.columns[:0]
+	for _, def := range lex.list() {
+		if len(def) == 0 || def[0].kind != sqlWord && def[0].kind != sqlIdent || isSQLConstraint(def) {
+			continue
+		}
+		column := def[0].text
+		t.columns = append(t.columns, column)
+		t.schema.declare(column, sqlColumnType(def[1:]))
+	}
+}
+
+// isSQLConstraint отличает ограничение или индекс от колонки. KEY и INDEX в
+// PostgreSQL допустимы как имена колонок, поэтому индексом MySQL
+// (KEY `name` (...)) они считаются только перед списком колонок.
+func isSQLConstraint(def []sqlToken) bool {
+	if def[0].kind != sqlWord {
+		return false
+	}
+	if containsFold(sqlConstraintWords, def[0].text) {
+		return true
+	}
+	if def[0].isWord("KEY") || def[0].isWord("INDEX") {
+		return len(def) > 1 && def[1].isSymbol("(") ||
+			len(def) > 2 && def[1].kind == sqlIdent && def[2].isSymbol("(")
+	}
+	return false
+}
+
+// sqlColumnType собирает тип колонки из начала ее описания.
+func sqlColumnType(def []sqlToken) string {
+	var sb strings.Builder
+	for i := 0; i < len(def); i++ {
+		tok := def[i]
+		switch {
+		case i == 0 && (tok.kind == sqlWord || tok.kind == sqlIdent):
+			sb.WriteString(tok.text)
+		case tok.isSymbol("(") || tok.isSymbol("["):
+			// Аргументы типа: varchar(255), decimal(10,2), text[]
+			depth := 0
+			for ; i < len(def); i++ {
+				sb.WriteString(def[i].text)
+				if def[i].isSymbol("(") || def[i].isSymbol("[") {
+					depth++
+				} else if def[i].isSymbol(")") || def[i].isSymbol("]") {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+		case tok.kind == sqlWord && containsFold(sqlTypeWords, tok.text):
+			sb.WriteString(" " + tok.text)
+		default:
+			return sb.String()
+		}
+	}
+	return sb.String()
+}
+
+func (d *sqlDump) insert(lex *sqlLexer) {
+	tok, ok := lex.next()
+	for ok && tok.kind == sqlWord && containsFold([]string{"IGNORE", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY"}, tok.text) {
+		tok, ok = lex.next()
+	}
+	if tok.isWord("INTO") {
+		tok, _ = lex.next()
+	}
+	name, tok, ok := lex.qualifiedName(tok)
+	if !ok {
+		return
+	}
+	var columns []string
+	if tok.isSymbol("(") {
+		columns = sqlColumnNames(lex.list())
+		tok, _ = lex.next()
+	}
+	if tok.isWord("OVERRIDING") {
+		lex.next() // SYSTEM | USER
+		lex.next() // VALUE
+		tok, _ = lex.next()
+	}
+	// INSERT ... SELECT и т.п. значений не содержат
+	if !tok.isWord("VALUES") && !tok.isWord("VALUE") {
+		return
+	}
+
+	t := d.table(name)
+	if columns == nil {
+		columns = t.columns
+	}
+
+	for t.accepts() {
+		tok, ok := lex.next()
+		if !ok || !tok.isSymbol("(") {
+			lex.unread()
+			return
+		}
+		row := lex.list()
+		values := make([]string, len(row))
+		present := make([]bool, len(row))
+		for i, expr := range row {
+			values[i], present[i] = sqlLiteral(expr)
+		}
+		if columns == nil {
+			columns = headerNames(make([]string, len(row)))
+		}
+		t.addRow(columns, values, present)
+
+		if tok, ok := lex.next(); !ok || !tok.isSymbol(",") {
+			lex.unread()
+			return
+		}
+	}
+}
+
+func sqlColumnNames(list [][]sqlToken) []string {
+	var names []string
+	for _, col := range list {
+		if len(col) > 0 {
+			names = append(names, col[0].text)
+		}
+	}
+	return names
+}
+
+// sqlLiteral извлекает значение из выражения в VALUES: строку (в том числе
+// внутри CAST и функций преобразования) или число. NULL, двоичные данные и
+// прочие выражения пропускаются.
+func sqlLiteral(expr []sqlToken) (string, bool) {
+	var number strings.Builder
+	for _, tok := range expr {
+		switch tok.kind {
+		case sqlBinary:
+			return "", false
+		case sqlWord:
+			if strings.EqualFold(tok.text, "_binary") {
+				return "", false
+			}
+		case sqlString:
+			return tok.text, true
+		case sqlNumber:
+			number.WriteString(tok.text)
+		case sqlSymbol:
+			if tok.text == "-" || tok.text == "+" {
+				number.WriteString(tok.text)
+			}
+		}
+	}
+	if number.Len() == 0 {
+		return "", false
+	}
+	return number.String(), true
+}
+
+// copy читает данные COPY ... FROM stdin в формате text: колонки разделены
+// табуляцией, блок завершается строкой "\.".
+func (d *sqlDump) copy(lex *sqlLexer) error {
+	tok, _ := lex.next()
+	if tok.isWord("ONLY") {
+		tok, _ = lex.next()
+	}
+	name, tok, ok := lex.qualifiedName(tok)
+	if !ok {
+		return nil
+	}
+	var columns []string
+	if tok.isSymbol("(") {
+		columns = sqlColumnNames(lex.list())
+		tok, _ = lex.next()
+	}
+	if next, _ := lex.next(); !tok.isWord("FROM") || !next.isWord("STDIN") {
+		return nil
+	}
+
+	t := d.table(name)
+	if columns == nil {
+		columns = t.columns
+	}
+
+	d.scanner.skipLine()
+	for {
+		line, err := d.scanner.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == `\.` {
+			return nil
+		}
+
+		if line != "" && t.accepts() {
+			fields := strings.Split(line, "\t")
+			values := make([]string, len(fields))
+			present := make([]bool, len(fields))
+			for i, field := range fields {
+				if field != `\N` {
+					values[i], present[i] = copyUnescape(field), true
+				}
+			}
+			if columns == nil {
+				columns = headerNames(make([]string, len(fields)))
+			}
+			t.addRow(columns, values, present)
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// copyUnescape раскрывает экранирование формата text команды COPY.
+func copyUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// sqlScanner делит дамп на операторы по ';' вне строк и комментариев, а
+// также по строкам GO (SQL Server). Комментарии из операторов удаляются.
+type sqlScanner struct {
+	r *bufio.Reader
+	// backslashEscapes — обратная косая черта экранирует символы в строках,
+	// как в дампах MySQL
+	backslashEscapes bool
+	buf              []byte
+}
+
+func (s *sqlScanner) next() (string, error) {
+	s.buf = s.buf[:0]
+	lineStart := 0
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			if stmt := strings.TrimSpace(string(s.buf)); stmt != "" {
+				return stmt, nil
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case ';':
+			if stmt := strings.TrimSpace(string(s.buf)); stmt != "" {
+				return stmt, nil
+			}
+			s.buf, lineStart = s.buf[:0], 0
+		case '\n':
+			if strings.EqualFold(strings.TrimSpace(string(s.buf[lineStart:])), "GO") {
+				// Скрипты SQL Server не экранируют строки обратной косой чертой
+				s.backslashEscapes = false
+				s.buf = s.buf[:lineStart]
+				if stmt := strings.TrimSpace(string(s.buf)); stmt != "" {
+					return stmt, nil
+				}
+				s.buf, lineStart = s.buf[:0], 0
+				continue
+			}
+			s.buf = append(s.buf, c)
+			lineStart = len(s.buf)
+		case '\'', '"', '`':
+			if err := s.quoted(c, c); err != nil {
+				return "", err
+			}
+		case '[':
+			if n := len(s.buf); n > 0 && isSQLWordByte(s.buf[n-1]) {
+				s.buf = append(s.buf, c) // text[] в PostgreSQL
+				continue
+			}
+			if err := s.quoted('[', ']'); err != nil {
+				return "", err
+			}
+		case '-':
+			if next, _ := s.r.Peek(1); len(next) == 1 && next[0] == '-' {
+				// Перевод строки остается: по нему распознается GO
+				for {
+					next, err := s.r.Peek(1)
+					if err != nil || next[0] == '\n' {
+						break
+					}
+					s.r.ReadByte()
+				}
+				continue
+			}
+			s.buf = append(s.buf, c)
+		case '/':
+			if next, _ := s.r.Peek(1); len(next) == 1 && next[0] == '*' {
+				s.r.ReadByte()
+				s.skipBlockComment()
+				s.buf = append(s.buf, ' ')
+				continue
+			}
+			s.buf = append(s.buf, c)
+		case '$':
+			if n := len(s.buf); n > 0 && isSQLWordByte(s.buf[n-1]) {
+				s.buf = append(s.buf, c)
+				continue
+			}
+			if err := s.dollarQuoted(); err != nil {
+				return "", err
+			}
+		default:
+			s.buf = append(s.buf, c)
+		}
+	}
+}
+
+// quoted копирует в буфер строку или идентификатор в кавычках вместе с
+// кавычками; удвоенная закрывающая кавычка считается частью значения.
+func (s *sqlScanner) quoted(open, close byte) error {
+	s.buf = append(s.buf, open)
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.buf = append(s.buf, c)
+		switch {
+		case c == '\\' && open == '\'' && s.backslashEscapes:
+			c, err := s.r.ReadByte()
+			if err != nil {
+				return nil
+			}
+			s.buf = append(s.buf, c)
+		case c == close:
+			if next, _ := s.r.Peek(1); len(next) == 1 && next[0] == close {
+				s.r.ReadByte()
+				s.buf = append(s.buf, close)
+				continue
+			}
+			return nil
+		}
+	}
+}
+
+// dollarQuoted пропускает строки $$...$$ и $tag$...$tag$ (тела функций в
+// pg_dump), заменяя их пустой строкой.
+func (s *sqlScanner) dollarQuoted() error {
+	peek, _ := s.r.Peek(64)
+	end := bytes.IndexByte(peek, '$')
+	if end < 0 || bytes.ContainsFunc(peek[:end], func(r rune) bool { return r >= 0x80 || !isSQLWordByte(byte(r)) }) {
+		s.buf = append(s.buf, '$')
+		return nil
+	}
+	tag := "$" + string(peek[:end+1])
+	s.r.Discard(end + 1)
+
+	var body []byte
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		body = append(body, c)
+		if bytes.HasSuffix(body, []byte(tag)) {
+			s.buf = append(s.buf, "''"...)
+			return nil
+		}
+	}
+}
+
+func (s *sqlScanner) skipBlockComment() {
+	var prev byte
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil || prev == '*' && c == '/' {
+			return
+		}
+		prev = c
+	}
+}
+
+// skipLine дочитывает текущую строку после оператора.
+func (s *sqlScanner) skipLine() {
+	s.r.ReadString('\n')
+}
+
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || c == '@' || c >= 0x80 ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+type sqlTokenKind int
+
+const (
+	sqlWord   sqlTokenKind = iota
+	sqlIdent               // идентификатор в кавычках
+	sqlString              // строковая константа
+	sqlNumber
+	sqlBinary // X'..', 0x..
+	sqlSymbol
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+func (t sqlToken) isWord(word string) bool {
+	return t.kind == sqlWord && strings.EqualFold(t.text, word)
+}
+
+func (t sqlToken) isSymbol(symbol string) bool {
+	return t.kind == sqlSymbol && t.text == symbol
+}
+
+// sqlLexer разбирает один оператор на лексемы; кавычки у строк и
+// идентификаторов снимаются.
+type sqlLexer struct {
+	src              string
+	pos              int
+	last             int // начало последней прочитанной лексемы
+	backslashEscapes bool
+}
+
+func (l *sqlLexer) next() (sqlToken, bool) {
+	l.last = l.pos
+	for l.pos < len(l.src) && strings.IndexByte(" \t\r\n", l.src[l.pos]) >= 0 {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return sqlToken{}, false
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '\'':
+		return sqlToken{kind: sqlString, text: l.quoted('\'')}, true
+	case c == '"' || c == '`':
+		return sqlToken{kind: sqlIdent, text: l.quoted(c)}, true
+	case c == '[' && (start == 0 || !isSQLWordByte(l.src[start-1])):
+		return sqlToken{kind: sqlIdent, text: l.quoted(']')}, true
+	case c >= '0' && c <= '9' || c == '.' && l.pos+1 < len(l.src) && l.src[l.pos+1] >= '0' && l.src[l.pos+1] <= '9':
+		for l.pos < len(l.src) && (isSQLWordByte(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		text := l.src[start:l.pos]
+		if strings.HasPrefix(strings.ToLower(text), "0x") {
+			return sqlToken{kind: sqlBinary, text: text}, true
+		}
+		return sqlToken{kind: sqlNumber, text: text}, true
+	case isSQLWordByte(c):
+		for l.pos < len(l.src) && isSQLWordByte(l.src[l.pos]) {
+			l.pos++
+		}
+		word := l.src[start:l.pos]
+		if l.pos < len(l.src) && l.src[l.pos] == '\'' {
+			// Префиксы строк: N'..', E'..', _utf8mb4'..', X'..', B'..'
+			text := l.quoted('\'')
+			switch strings.ToUpper(word) {
+			case "X", "B", "_BINARY":
+				return sqlToken{kind: sqlBinary, text: text}, true
+			}
+			return sqlToken{kind: sqlString, text: text}, true
+		}
+		return sqlToken{kind: sqlWord, text: word}, true
+	}
+	l.pos++
+	return sqlToken{kind: sqlSymbol, text: string(c)}, true
+}
+
+// unread возвращает последнюю прочитанную лексему.
+func (l *sqlLexer) unread() {
+	l.pos = l.last
+}
+
+// nextStatement пропускает остаток оператора и возвращает первое слово
+// следующего из sqlStatements.
+func (l *sqlLexer) nextStatement() (sqlToken, bool) {
+	depth := 0
+	for {
+		tok, ok := l.next()
+		switch {
+		case !ok:
+			return tok, false
+		case tok.isSymbol("("):
+			depth++
+		case tok.isSymbol(")"):
+			depth--
+		case depth <= 0 && tok.kind == sqlWord && containsFold(sqlStatements, tok.text):
+			return tok, true
+		}
+	}
+}
+
+// quoted читает значение в кавычках начиная с открывающей и снимает
+// экранирование.
+func (l *sqlLexer) quoted(close byte) string {
+	var sb strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		l.pos++
+		switch {
+		case c == '\\' && close == '\'' && l.backslashEscapes && l.pos < len(l.src):
+			e := l.src[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			default:
+				sb.WriteByte(e)
+			}
+		case c == close:
+			if l.pos < len(l.src) && l.src[l.pos] == close {
+				sb.WriteByte(close)
+				l.pos++
+				continue
+			}
+			return sb.String()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// qualifiedName читает имя вида schema.table, начиная с лексемы first, и
+// возвращает его вместе со следующей за ним лексемой.
+func (l *sqlLexer) qualifiedName(first sqlToken) (string, sqlToken, bool) {
+	if first.kind != sqlWord && first.kind != sqlIdent {
+		return "", sqlToken{}, false
+	}
+	parts := []string{first.text}
+	for {
+		tok, ok := l.next()
+		if !tok.isSymbol(".") {
+			return strings.Join(parts, "."), tok, true
+		}
+		if tok, ok = l.next(); !ok || tok.kind != sqlWord && tok.kind != sqlIdent {
+			return "", sqlToken{}, false
+		}
+		parts = append(parts, tok.text)
+	}
+}
+
+// list читает элементы списка в скобках после открывающей скобки до
+// парной закрывающей; элементы разделены запятыми верхнего уровня.
+func (l *sqlLexer) list() [][]sqlToken {
+	var items [][]sqlToken
+	var item []sqlToken
+	depth := 0
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return append(items, item)
+		}
+		if tok.kind == sqlSymbol {
+			switch tok.text {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				if depth == 0 {
+					return append(items, item)
+				}
+				depth--
+			case ",":
+				if depth == 0 {
+					items = append(items, item)
+					item = nil
+					continue
+				}
+			}
+		}
+		item = append(item, tok)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestReadSQLDump проверяет разбор дампов разных СУБД: таблицы, колонки и
+// значения выборки.
+func TestReadSQLDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		dump    string
+		table   string
+		columns []string
+		values  map[string][]string
+	}{
+		{
+			name: "mysqldump",
+			dump: "-- MySQL dump 10.13\n" +
+				"/*!40101 SET NAMES utf8mb4 */;\n" +
+				"CREATE TABLE `clients` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` varchar(100) DEFAULT NULL,\n" +
+				"  `comment` text,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB;\n" +
+				"INSERT INTO `clients` VALUES (1,'O\\'Brien','say \\\"hi\\\"; ok'),(2,'D''Arcy',NULL);\n",
+			table:   "clients",
+			columns: []string{"id", "name", "comment"},
+			values: map[string][]string{
+				"id":      {"1", "2"},
+				"name":    {"O'Brien", "D'Arcy"},
+				"comment": {`say "hi"; ok`},
+			},
+		},
+		{
+			name: "pg_dump",
+			dump: "SET standard_conforming_strings = on;\n" +
+				"CREATE TABLE public.persons (\n" +
+				"    id integer NOT NULL,\n" +
+				"    full_name text,\n" +
+				"    phone character varying(20)\n" +
+				");\n" +
+				"COPY public.persons (id, full_name, phone) FROM stdin;\n" +
+				"1\tИванов Иван Иванович\t\\N\n" +
+				"2\tПетрова\\tАнна\t+79161234567\n" +
+				"\\.\n" +
+				"ALTER TABLE ONLY public.persons ADD CONSTRAINT persons_pkey PRIMARY KEY (id);\n",
+			table:   "public.persons",
+			columns: []string{"id", "full_name", "phone"},
+			values: map[string][]string{
+				"id":        {"1", "2"},
+				"full_name": {"Иванов Иван Иванович", "Петрова\tАнна"},
+				"phone":     {"+79161234567"},
+			},
+		},
+		{
+			name: "SSMS",
+			dump: "USE [crm]\nGO\n" +
+				"SET ANSI_NULLS ON\nGO\n" +
+				"CREATE TABLE [dbo].[Customers](\n" +
+				"\t[Id] [int] IDENTITY(1,1) NOT NULL,\n" +
+				"\t[FullName] [nvarchar](200) NULL,\n" +
+				"\t[Email] [nvarchar](100) NULL\n" +
+				") ON [PRIMARY]\nGO\n" +
+				"INSERT [dbo].[Customers] ([Id], [FullName], [Email]) VALUES (1, N'Сидоров Петр', N'sidorov@example.com')\n" +
+				"INSERT [dbo].[Customers] ([Id], [FullName], [Email]) VALUES (2, N'Д''Артаньян', NULL)\nGO\n",
+			table:   "dbo.Customers",
+			columns: []string{"Id", "FullName", "Email"},
+			values: map[string][]string{
+				"Id":       {"1", "2"},
+				"FullName": {"Сидоров Петр", "Д'Артаньян"},
+				"Email":    {"sidorov@example.com"},
+			},
+		},
+		{
+			name: "quoted qualified name",
+			dump: `CREATE TABLE "Sales"."Order Items" ("Customer Name" text, "e-mail" text);` + "\n" +
+				`INSERT INTO "Sales"."Order Items" ("Customer Name", "e-mail") VALUES ('Кузнецова Мария', 'kuz@example.com');` + "\n",
+			table:   "Sales.Order Items",
+			columns: []string{"Customer Name", "e-mail"},
+			values: map[string][]string{
+				"Customer Name": {"Кузнецова Мария"},
+				"e-mail":        {"kuz@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables := readTestSQLDump(t, tt.dump)
+			if len(tables) != 1 {
+				t.Fatalf("найдено таблиц: %d, ожидалась одна", len(tables))
+			}
+			table := tables[0]
+			if table.name != tt.table {
+				t.Errorf("таблица = %q, ожидалась %q", table.name, tt.table)
+			}
+			var columns []string
+			for _, col := range table.schema.columns {
+				columns = append(columns, col.ColumnName)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("колонки = %q, ожидались %q", columns, tt.columns)
+			}
+			for column, want := range tt.values {
+				if got := table.schema.values[column]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: значения = %q, ожидались %q", column, got, want)
+				}
+			}
+		})
+	}
+}
+
+func readTestSQLDump(t *testing.T, dump string) []fileTable {
+	t.Helper()
+	f := writeTestFile(t, "dump.sql", []byte(dump))
+	tables, err := readSQLDumpFile(f, int64(len(dump)), 10)
+	if err != nil {
+		t.Fatalf("readSQLDumpFile: %v", err)
+	}
+	return tables
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// fileCase — содержимое файла и ожидаемые колонки с типами и значениями.
+type fileCase struct {
+	name    string
+	file    string
+	data    []byte
+	columns []ColumnInfo
+	values  map[string][]string
+}
+
+func TestReadTextFiles(t *testing.T) {
+	cp1251, err := charmap.Windows1251.NewEncoder().String(
+		"ФИО;Телефон;Город\r\nИванов Иван Иванович;+79161234567;Москва\r\nПетрова Анна;+79031234567;Тверь\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(
+		"Фамилия,Email\r\nСидоров,sidorov@example.com\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []fileCase{
+		{
+			name: "CSV в Windows-1251 с разделителем ;",
+			file: "clients.csv",
+			data: []byte(cp1251),
+			columns: []ColumnInfo{
+				{ColumnName: "ФИО", DataType: "text"},
+				{ColumnName: "Телефон", DataType: "text"},
+				{ColumnName: "Город", DataType: "text"},
+			},
+			values: map[string][]string{
+				"ФИО":     {"Иванов Иван Иванович", "Петрова Анна"},
+				"Телефон": {"+79161234567", "+79031234567"},
+				"Город":   {"Москва", "Тверь"},
+			},
+		},
+		{
+			name: "CSV в UTF-16LE с BOM",
+			file: "export.csv",
+			data: []byte(utf16),
+			columns: []ColumnInfo{
+				{ColumnName: "Фамилия", DataType: "text"},
+				{ColumnName: "Email", DataType: "text"},
+			},
+			values: map[string][]string{
+				"Фамилия": {"Сидоров"},
+				"Email":   {"sidorov@example.com"},
+			},
+		},
+		{
+			name: "TSV с пустым и повторяющимся заголовком",
+			file: "staff.tsv",
+			data: []byte("\xef\xbb\xbfname\tphone, mobile\t\tname\n Иван \t+79161234567\tx\tИванов\n"),
+			columns: []ColumnInfo{
+				{ColumnName: "name", DataType: "text"},
+				{ColumnName: "phone, mobile", DataType: "text"},
+				{ColumnName: "column_3", DataType: "text"},
+				{ColumnName: "name_2", DataType: "text"},
+			},
+			values: map[string][]string{
+				"name":          {"Иван"},
+				"phone, mobile": {"+79161234567"},
+				"name_2":        {"Иванов"},
+			},
+		},
+		{
+			name: "JSON Lines с вложенными объектами",
+			file: "events.jsonl",
+			data: []byte(`{"user": {"name": "Иван", "contacts": {"phones": [{"number": "+79161234567"}, {"number": "+79031234567"}]}}, "age": 30}` + "\n\n" +
+				`{"user": {"name": "Анна", "contacts": {"phones": []}}, "age": null}` + "\n"),
+			columns: []ColumnInfo{
+				{ColumnName: "user.name", DataType: "string"},
+				{ColumnName: "user.contacts.phones[].number", DataType: "string"},
+				{ColumnName: "age", DataType: "int"},
+			},
+			values: map[string][]string{
+				"user.name":                     {"Иван", "Анна"},
+				"user.contacts.phones[].number": {"+79161234567", "+79031234567"},
+				"age":                           {"30"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := writeTestFile(t, tt.file, tt.data)
+			tables, err := fileReaders[filepath.Ext(tt.file)](f, int64(len(tt.data)), 10)
+			if err != nil {
+				t.Fatalf("чтение: %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("найдено таблиц: %d, ожидалась одна", len(tables))
+			}
+			checkFileTable(t, tables[0], tt.columns, tt.values)
+		})
+	}
+}
+
+func TestReadExcelFile(t *testing.T) {
+	book := excelize.NewFile()
+	defer book.Close()
+	rows := [][]any{
+		{},
+		{"ФИО", "", "Телефон"},
+		{"Иванов Иван", "x", "+79161234567"},
+		{" Петрова Анна ", nil, 79031234567},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := book.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := book.NewSheet("Пустой"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	if err := book.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tables, err := readExcelFile(f, 0, 10)
+	if err != nil {
+		t.Fatalf("readExcelFile: %v", err)
+	}
+	if len(tables) != 2 || tables[0].name != "Sheet1" || tables[1].name != "Пустой" {
+		t.Fatalf("листы: %+v", tables)
+	}
+	checkFileTable(t, tables[0],
+		[]ColumnInfo{
+			{ColumnName: "ФИО", DataType: "text"},
+			{ColumnName: "column_2", DataType: "text"},
+			{ColumnName: "Телефон", DataType: "text"},
+		},
+		map[string][]string{
+			"ФИО":     {"Иванов Иван", "Петрова Анна"},
+			"Телефон": {"+79161234567", "79031234567"},
+		})
+	if n := len(tables[1].schema.columns); n != 0 {
+		t.Errorf("на пустом листе найдено колонок: %d", n)
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		head string
+		want rune
+	}{
+		{"name,email,phone\n", ','},
+		{"ФИО;Телефон;Адрес\nИванов, Иван;1;г. Москва, ул. Ленина\n", ';'},
+		{"name\temail\n", '\t'},
+		{"name\n", ','},
+		{"\"Иванов, Иван\";1\n", ','},
+	}
+	for _, tt := range tests {
+		if got := detectDelimiter([]byte(tt.head)); got != tt.want {
+			t.Errorf("%q: %q, ожидался %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderNames(t *testing.T) {
+	got := headerNames([]string{" id ", "", "name", "name", "", "name"})
+	want := []string{"id", "column_2", "name", "name_2", "column_5", "name_3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("headerNames = %q, ожидалось %q", got, want)
+	}
+}
+
+func checkFileTable(t *testing.T, table fileTable, columns []ColumnInfo, values map[string][]string) {
+	t.Helper()
+	if !reflect.DeepEqual(table.schema.columns, columns) {
+		t.Errorf("колонки = %+v, ожидались %+v", table.schema.columns, columns)
+	}
+	for column, want := range values {
+		if got := table.schema.values[column]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: значения = %q, ожидались %q", column, got, want)
+		}
+	}
+}
+
+// writeTestFile записывает файл во временный каталог и открывает его.
+func writeTestFile(t *testing.T, name string, data []byte) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
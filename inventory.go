@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory — список серверов для массовой проверки:
+//
+//	servers:
+//	  - name: hr-prod
+//	    server: srv1mssql12
+//	    port: "1433"
+//	    all_databases: true
+//	    user: auditor
+//	    credentials_file: /etc/pdn/hr.cred
+//
+// Незаданные поля берутся из общих параметров запуска.
+type Inventory struct {
+	Servers []InventoryServer `yaml:"servers"`
+}
+
+type InventoryServer struct {
+	Name            string `yaml:"name"`
+	Driver          string `yaml:"driver"`
+	Server          string `yaml:"server"`
+	Port            string `yaml:"port"`
+	Database        string `yaml:"database"`
+	AllDatabases    bool   `yaml:"all_databases"`
+	User            string `yaml:"user"`
+	PasswordFile    string `yaml:"password_file"`
+	PasswordEnv     string `yaml:"password_env"`
+	CredentialsFile string `yaml:"credentials_file"`
+	Auth            string `yaml:"auth"`
+	Krb5Realm       string `yaml:"krb5_realm"`
+	Keytab          string `yaml:"keytab"`
+	AADClientID     string `yaml:"aad_client_id"`
+	AADTenantID     string `yaml:"aad_tenant_id"`
+	HostInCert      string `yaml:"host_in_cert"`
+}
+
+type ServerStatus struct {
+	Name     string
+	Server   string
+	Status   string
+	Error    string
+	Security string
+	Records  int
+	Started  time.Time
+	Duration time.Duration
+}
+
+func loadInventory(fileName string) (*Inventory, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("чтение списка серверов: %v", err)
+	}
+
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("разбор списка серверов %s: %v", fileName, err)
+	}
+	if len(inv.Servers) == 0 {
+		return nil, fmt.Errorf("в списке серверов %s нет ни одного сервера", fileName)
+	}
+	for i, s := range inv.Servers {
+		if s.Server == "" {
+			return nil, fmt.Errorf("список серверов %s: у записи %d не указан server", fileName, i+1)
+		}
+	}
+
+	return &inv, nil
+}
+
+// scanInventory проверяет серверы из списка, не более opts.Parallel
+// одновременно, и возвращает статус по каждому серверу в порядке списка.
+func scanInventory(opts *Options, inv *Inventory, resultsChan chan<- PDNResult) []ServerStatus {
+	statuses := make([]ServerStatus, len(inv.Servers))
+	sem := make(chan struct{}, opts.Parallel)
+	var wg sync.WaitGroup
+
+	for i, entry := range inv.Servers {
+		wg.Add(1)
+		go func(i int, entry InventoryServer) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			statuses[i] = scanInventoryServer(opts, entry, resultsChan)
+		}(i, entry)
+	}
+
+	wg.Wait()
+	return statuses
+}
+
+func scanInventoryServer(base *Options, entry InventoryServer, resultsChan chan<- PDNResult) ServerStatus {
+	status := ServerStatus{
+		Name:    entry.Name,
+		Server:  entry.Server,
+		Started: time.Now(),
+	}
+	if status.Name == "" {
+		status.Name = entry.Server
+	}
+
+	fmt.Printf("\n######## Сервер %s ########\n", status.Name)
+
+	// Результаты пересылаются через промежуточный канал, чтобы посчитать
+	// количество записей по серверу и узнать защиту соединения
+	serverChan := make(chan PDNResult, 100)
+	forwarded := make(chan int)
+	go func() {
+		count := 0
+		for r := range serverChan {
+			if status.Security == "" {
+				status.Security = r.ConnectionSecurity
+			}
+			resultsChan <- r
+			count++
+		}
+		forwarded <- count
+	}()
+
+	opts, err := entry.options(base)
+	if err == nil {
+		err = scanServer(opts, serverChan)
+	}
+	close(serverChan)
+
+	status.Records = <-forwarded
+	status.Duration = time.Since(status.Started)
+	var dbErrs *databaseErrors
+	switch {
+	case errors.As(err, &dbErrs) && dbErrs.partial():
+		status.Status = "Частично"
+		status.Error = err.Error()
+		log.Printf("⚠ Сервер %s проверен частично, записей: %d: %v\n", status.Name, status.Records, err)
+	case err != nil:
+		status.Status = "Ошибка"
+		status.Error = err.Error()
+		log.Printf("✗ Сервер %s: %v\n", status.Name, err)
+	default:
+		status.Status = "Успешно"
+		fmt.Printf("\n✓ Сервер %s проверен, записей: %d\n", status.Name, status.Records)
+	}
+
+	return status
+}
+
+// options возвращает параметры подключения к серверу из списка поверх общих.
+func (e InventoryServer) options(base *Options) (*Options, error) {
+	opts := *base
+	opts.Server = e.Server
+	if base.defaultPort {
+		// Порт по умолчанию для сервера из записи подставит resolveDialect
+		opts.Port = ""
+	}
+	if e.Driver != "" && e.Driver != base.Driver {
+		opts.Driver = e.Driver
+		// Порт по умолчанию другой СУБД подставит resolveDialect
+		opts.Port = ""
+	}
+	if e.Port != "" {
+		opts.Port = e.Port
+	}
+	if e.Database != "" {
+		opts.Database = e.Database
+		opts.AllDatabases = false
+	}
+	if e.AllDatabases {
+		opts.AllDatabases = true
+	}
+	if e.User != "" {
+		opts.User = e.User
+	}
+	if e.Auth != "" {
+		opts.Auth.Mode = e.Auth
+	}
+	if e.Krb5Realm != "" {
+		opts.Auth.Krb5Realm = e.Krb5Realm
+	}
+	if e.Keytab != "" {
+		opts.Auth.Keytab = e.Keytab
+	}
+	if e.AADClientID != "" {
+		opts.Auth.AADClientID = e.AADClientID
+	}
+	if e.AADTenantID != "" {
+		opts.Auth.AADTenantID = e.AADTenantID
+	}
+	if e.HostInCert != "" {
+		opts.TLS.HostInCert = e.HostInCert
+	}
+	if err := opts.resolveDialect(); err != nil {
+		return nil, err
+	}
+
+	if e.PasswordFile != "" || e.PasswordEnv != "" || e.CredentialsFile != "" {
+		opts.Password = ""
+		opts.PasswordStdin = false
+		opts.PasswordFile = e.PasswordFile
+		opts.PasswordEnv = e.PasswordEnv
+		opts.CredentialsFile = e.CredentialsFile
+		if err := opts.resolveCredentials(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case opts.Database == "" && !opts.AllDatabases:
+		return nil, errors.New("не указана БД (database или all_databases)")
+	case opts.User == "" && opts.needsUser():
+		return nil, errors.New("не указан логин")
+	case opts.Password == "" && opts.needsPassword():
+		return nil, errors.New("не задан пароль")
+	}
+
+	return &opts, nil
+}
+
+func statusFileName(reportFileName string) string {
+	return strings.TrimSuffix(reportFileName, ".csv") + "_status.csv"
+}
+
+func saveServerStatuses(fileName string, statuses []ServerStatus) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"Имя", "Сервер", "Статус", "Ошибка", "Защита соединения", "Записей в отчете", "Начало", "Длительность"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		record := []string{
+			s.Name,
+			s.Server,
+			s.Status,
+			s.Error,
+			s.Security,
+			strconv.Itoa(s.Records),
+			s.Started.Format(time.RFC3339),
+			s.Duration.Round(time.Second).String(),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
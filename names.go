@@ -0,0 +1,194 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// columnName — имя колонки, разобранное на слова для сравнения с
+// ключевыми словами правил.
+type columnName struct {
+	lower string
+	// variants — слова имени после раскрытия сокращений: как есть и с
+	// латиницей, переведенной в кириллицу (familiya → фамилия)
+	variants [][]string
+}
+
+// parseColumnName разбирает имя колонки на слова (см. splitName) и
+// раскрывает сокращения из словаря abbreviations.
+func parseColumnName(name string, abbreviations map[string]string) columnName {
+	var words []string
+	for _, w := range splitName(name) {
+		if full, ok := abbreviations[w]; ok {
+			words = append(words, splitName(full)...)
+		} else {
+			words = append(words, w)
+		}
+	}
+
+	cn := columnName{lower: strings.ToLower(name), variants: [][]string{words}}
+
+	var translit []string
+	changed := false
+	for _, w := range words {
+		t := transliterate(w)
+		if t == w {
+			translit = append(translit, w)
+			continue
+		}
+		changed = true
+		if full, ok := abbreviations[t]; ok {
+			translit = append(translit, splitName(full)...)
+		} else {
+			translit = append(translit, t)
+		}
+	}
+	if changed {
+		cn.variants = append(cn.variants, translit)
+	}
+	return cn
+}
+
+// containsAny сообщает, что в имени (как есть или в виде слов через
+// пробел) есть одна из подстрок.
+func (cn columnName) containsAny(substrings []string) bool {
+	if containsAny(cn.lower, substrings) {
+		return true
+	}
+	for _, words := range cn.variants {
+		if containsAny(strings.Join(words, " "), substrings) {
+			return true
+		}
+	}
+	return false
+}
+
+// keywordStrength оценивает, насколько ключевое слово keyword (слова
+// ключевого слова, записанные слитно: last name → lastname) совпадает со
+// словами имени. Ключевое слово может занимать несколько слов подряд
+// (last_name, lastName) и быть началом слова (фамил в фамилия). Короткие
+// ключевые слова (до трех букв) совпадают только с целым словом: пол не
+// находится в полис, tel — в hotel. Возвращает 0, если совпадения нет,
+// и номер варианта имени из variants, в котором оно найдено.
+func (cn columnName) keywordStrength(keyword string) (float64, int) {
+	short := len([]rune(keyword)) <= 3
+	best, variant := 0.0, 0
+	for v, words := range cn.variants {
+		strength := 0.0
+		for i := range words {
+			joined := ""
+			for _, w := range words[i:] {
+				joined += w
+				if joined == keyword {
+					strength = max(strength, keywordWord)
+					break
+				}
+				if len(joined) >= len(keyword) {
+					if !short && strings.HasPrefix(joined, keyword) {
+						strength = max(strength, keywordStem)
+					}
+					break
+				}
+				if !strings.HasPrefix(keyword, joined) {
+					break
+				}
+			}
+
+			if len([]rune(keyword)) > 4 && strings.Contains(words[i][1:], keyword) {
+				strength = max(strength, keywordInside)
+			}
+		}
+		if strength > best {
+			best, variant = strength, v
+		}
+	}
+	return best, variant
+}
+
+// splitName делит имя на слова в нижнем регистре: по разделителям, по
+// переходу от строчной буквы к заглавной (lastName), от цифр к буквам и
+// между латиницей и кириллицей. Аббревиатура перед словом отделяется от
+// него: HTTPServer → http, server.
+func splitName(name string) []string {
+	runes := []rune(name)
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(word) > 0 {
+			prev := word[len(word)-1]
+			switch {
+			case unicode.IsDigit(prev) != unicode.IsDigit(r):
+				flush()
+			case isLatin(prev) != isLatin(r):
+				flush()
+			case unicode.IsLower(prev) && unicode.IsUpper(r):
+				flush()
+			case unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+func isLatin(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// translitPairs — распространенные способы записи русских букв латиницей,
+// более длинные сочетания раньше.
+var translitPairs = []struct{ latin, cyrillic string }{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"},
+	{"g", "г"}, {"h", "х"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"},
+	{"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"},
+	{"y", "ы"}, {"z", "з"},
+}
+
+// transliterate переводит слово из латиницы в кириллицу: familiya →
+// фамилия, rozhd → рожд. Слова не только из латинских букв
+// возвращаются как есть.
+func transliterate(word string) string {
+	for _, r := range word {
+		if !isLatin(r) {
+			return word
+		}
+	}
+
+	var b strings.Builder
+	for rest := word; rest != ""; {
+		// Окончания прилагательных: lichnyy → личный, domashniy → домашний
+		switch rest {
+		case "iy":
+			b.WriteString("ий")
+			return b.String()
+		case "yy":
+			b.WriteString("ый")
+			return b.String()
+		}
+		for _, p := range translitPairs {
+			if strings.HasPrefix(rest, p.latin) {
+				b.WriteString(p.cyrillic)
+				rest = rest[len(p.latin):]
+				break
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestMatchHeader проверяет, какие правила находит имя колонки и с какой
+// силой совпадения: короткие ключевые слова — только целым словом,
+// сокращения и транслит раскрываются.
+func TestMatchHeader(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string // id/сила через пробел
+	}{
+		{"пол", "gender/1"},
+		{"полис", "oms/1"},
+		{"gender", "gender/0.85"},
+		{"tel", "phone/1"},
+		{"hotel", ""},
+		{"cellphone", "phone/0.5"},
+		{"LastName", "fio/1"},
+		{"имя", "fio/1"},
+		{"имя_файла", ""},
+		{"hostname", ""},
+		{"familiya", "fio/1"},
+		{"nomer_pasporta", "passport/0.85"},
+		{"data_rozhd", "birth_date/1"},
+		{"dr", "birth_date/1"},
+		{"fio", "fio/1"},
+		{"ФИО_сотрудника", "fio/1 personal/0.85"},
+		{"tabn", "employee_number/1"},
+		{"tab_nom", "employee_number/0.85"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range rules.matchHeader(tt.name) {
+			got = append(got, fmt.Sprintf("%s/%v", m.rule.ID, m.strength))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q: %q, ожидалось %q", tt.name, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestMatchHeaderReading(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		reading string
+	}{
+		{"familiya", "фамилия"},
+		{"nomer_pasporta", "номер паспорта"},
+		{"dr", "дата рождения"},
+		{"tabn", "табельный номер"},
+		{"last_name", ""},
+	}
+	for _, tt := range tests {
+		matched := rules.matchHeader(tt.name)
+		if len(matched) == 0 {
+			t.Errorf("%q: правило не найдено", tt.name)
+			continue
+		}
+		if matched[0].reading != tt.reading {
+			t.Errorf("%q: прочитано %q, ожидалось %q", tt.name, matched[0].reading, tt.reading)
+		}
+	}
+}
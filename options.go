@@ -0,0 +1,399 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Options — параметры запуска проверки. Значения собираются по приоритету:
+// флаги командной строки, переменные окружения, профиль из файла
+// конфигурации, встроенные значения по умолчанию; недостающее
+// запрашивается интерактивно.
+type Options struct {
+	Driver   string
+	Server   string
+	Port     string
+	Database string
+	User     string
+	Password string
+	Output   string
+
+	PasswordFile    string
+	PasswordStdin   bool
+	PasswordEnv     string
+	CredentialsFile string
+
+	Auth AuthOptions
+	TLS  TLSOptions
+
+	ConfigFile string
+	Profile    string
+
+	Inventory string
+	Parallel  int
+
+	ScanFiles  string
+	S3Endpoint string
+
+	ConnectTimeout time.Duration
+	ListTimeout    time.Duration
+	TableTimeout   time.Duration
+	ColumnTimeout  time.Duration
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	SampleSize int
+	BatchSize  int
+
+	RulesFile string
+	// ConfirmedRatio и PossibleRatio, если заданы, заменяют пороги
+	// уверенности из правил
+	ConfirmedRatio float64
+	PossibleRatio  float64
+
+	AllDatabases     bool
+	IncludeDatabases []string
+	ExcludeDatabases []string
+
+	IncludeSchemas []string
+	ExcludeSchemas []string
+	IncludeTables  []string
+	ExcludeTables  []string
+
+	NonInteractive bool
+
+	backend Backend
+	// dialect задан, если backend — реляционная СУБД
+	dialect Dialect
+	rules   *RuleSet
+	// defaultPort — Port подставлен по умолчанию, а не задан пользователем
+	defaultPort bool
+}
+
+func defaultOptions() *Options {
+	return &Options{
+		Driver:          "mssql",
+		Auth:            AuthOptions{Mode: authSQL, Krb5Config: "/etc/krb5.conf"},
+		ConnectTimeout:  60 * time.Second,
+		ListTimeout:     5 * time.Minute,
+		TableTimeout:    5 * time.Minute,
+		ColumnTimeout:   60 * time.Second,
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: 15 * time.Minute,
+		SampleSize:      20,
+		BatchSize:       100,
+		Parallel:        4,
+	}
+}
+
+func parseOptions(args []string) (*Options, error) {
+	opts := defaultOptions()
+
+	fs := flag.NewFlagSet("pdn_checker", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: pdn_checker [флаги]\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nПеременные окружения: PDN_CONFIG, PDN_PROFILE, PDN_DRIVER, PDN_SERVER, PDN_PORT, PDN_DATABASE, PDN_USER,\n"+
+			"PDN_PASSWORD, PDN_PASSWORD_FILE, PDN_CREDENTIALS_FILE, PDN_AUTH, PDN_KEYTAB, PDN_AAD_CLIENT_ID,\n"+
+			"PDN_AAD_TENANT_ID, PDN_OUTPUT, PDN_S3_ENDPOINT, PDN_RULES\n")
+	}
+
+	fs.StringVar(&opts.ConfigFile, "config", os.Getenv("PDN_CONFIG"), "файл конфигурации YAML/JSON (PDN_CONFIG)")
+	fs.StringVar(&opts.Profile, "profile", os.Getenv("PDN_PROFILE"), "профиль из файла конфигурации (PDN_PROFILE)")
+	fs.StringVar(&opts.Inventory, "inventory", "", "файл YAML/JSON со списком серверов для массовой проверки")
+	fs.StringVar(&opts.ScanFiles, "scan-files", "", "каталог, файл или s3://bucket/prefix с выгрузками CSV/TSV/JSONL/XLSX/Parquet/Avro для проверки без БД")
+	fs.StringVar(&opts.S3Endpoint, "s3-endpoint", opts.S3Endpoint, "адрес S3-совместимого хранилища для s3://, например http://localhost:9000 (PDN_S3_ENDPOINT)")
+	fs.IntVar(&opts.Parallel, "parallel", opts.Parallel, "сколько серверов из --inventory проверять одновременно")
+	fs.StringVar(&opts.Driver, "driver", opts.Driver, "СУБД: "+strings.Join(dialectNames(), ", ")+" (PDN_DRIVER)")
+	fs.StringVar(&opts.Server, "server", opts.Server, "сервер БД (PDN_SERVER)")
+	fs.StringVar(&opts.Port, "port", opts.Port, "порт БД (PDN_PORT), по умолчанию стандартный для СУБД")
+	fs.StringVar(&opts.Database, "database", opts.Database, "имя БД (PDN_DATABASE)")
+	fs.StringVar(&opts.User, "user", opts.User, "логин (PDN_USER)")
+	fs.StringVar(&opts.PasswordFile, "password-file", opts.PasswordFile, "файл с паролем в первой строке (PDN_PASSWORD_FILE)")
+	fs.BoolVar(&opts.PasswordStdin, "password-stdin", false, "прочитать пароль из stdin (например, из канала)")
+	fs.StringVar(&opts.CredentialsFile, "credentials-file", opts.CredentialsFile, "файл с user=/password= и правами 600 (PDN_CREDENTIALS_FILE)")
+	fs.StringVar(&opts.Auth.Mode, "auth", opts.Auth.Mode, "способ входа: "+strings.Join(authModes, ", ")+" (PDN_AUTH)")
+	fs.StringVar(&opts.Auth.Krb5Config, "krb5-conf", opts.Auth.Krb5Config, "файл krb5.conf для --auth kerberos")
+	fs.StringVar(&opts.Auth.Krb5Realm, "krb5-realm", opts.Auth.Krb5Realm, "Kerberos realm для --auth kerberos")
+	fs.StringVar(&opts.Auth.Keytab, "keytab", opts.Auth.Keytab, "keytab-файл для --auth kerberos (PDN_KEYTAB)")
+	fs.StringVar(&opts.Auth.Krb5Cache, "krb5-ccache", opts.Auth.Krb5Cache, "кэш билетов Kerberos для --auth kerberos")
+	fs.StringVar(&opts.Auth.AADClientID, "aad-client-id", opts.Auth.AADClientID, "идентификатор приложения Azure AD (PDN_AAD_CLIENT_ID)")
+	fs.StringVar(&opts.Auth.AADTenantID, "aad-tenant-id", opts.Auth.AADTenantID, "идентификатор арендатора Azure AD (PDN_AAD_TENANT_ID)")
+	fs.StringVar(&opts.Auth.AADCert, "aad-cert", opts.Auth.AADCert, "сертификат субъекта-службы Azure AD вместо секрета")
+	fs.StringVar(&opts.TLS.Encrypt, "encrypt", opts.TLS.Encrypt, "шифрование соединения: "+strings.Join(encryptModes, ", ")+" (по умолчанию как в драйвере)")
+	fs.BoolVar(&opts.TLS.TrustServerCert, "trust-server-cert", false, "не проверять сертификат сервера")
+	fs.StringVar(&opts.TLS.CAFile, "ca-file", opts.TLS.CAFile, "PEM-файл с сертификатами CA для проверки сервера")
+	fs.StringVar(&opts.TLS.HostInCert, "host-in-cert", opts.TLS.HostInCert, "имя хоста, ожидаемое в сертификате сервера")
+	fs.StringVar(&opts.TLS.MinVersion, "tls-min", opts.TLS.MinVersion, "минимальная версия TLS: 1.0, 1.1, 1.2, 1.3")
+	fs.BoolVar(&opts.TLS.RequireEncryption, "require-encryption", false, "прервать проверку, если соединение не зашифровано")
+	fs.StringVar(&opts.Output, "output", opts.Output, "путь к файлу отчета (PDN_OUTPUT), по умолчанию report_<сервер>_<БД>.csv")
+	fs.DurationVar(&opts.ConnectTimeout, "connect-timeout", opts.ConnectTimeout, "таймаут проверки подключения")
+	fs.DurationVar(&opts.ListTimeout, "list-timeout", opts.ListTimeout, "таймаут получения списка таблиц")
+	fs.DurationVar(&opts.TableTimeout, "table-timeout", opts.TableTimeout, "таймаут анализа одной таблицы")
+	fs.DurationVar(&opts.ColumnTimeout, "column-timeout", opts.ColumnTimeout, "таймаут анализа одной колонки")
+	fs.IntVar(&opts.MaxOpenConns, "max-open-conns", opts.MaxOpenConns, "максимум открытых соединений с БД")
+	fs.IntVar(&opts.MaxIdleConns, "max-idle-conns", opts.MaxIdleConns, "максимум простаивающих соединений с БД")
+	fs.DurationVar(&opts.ConnMaxLifetime, "conn-max-lifetime", opts.ConnMaxLifetime, "время жизни соединения с БД")
+	fs.IntVar(&opts.SampleSize, "sample-size", opts.SampleSize, "количество значений, выбираемых из колонки")
+	fs.StringVar(&opts.RulesFile, "rules", opts.RulesFile, "файл YAML/JSON с правилами обнаружения ПДн, дополняющими встроенные (PDN_RULES)")
+	fs.Float64Var(&opts.ConfirmedRatio, "confirmed-ratio", opts.ConfirmedRatio, "уверенность, с которой ПДн в колонке подтверждены (по умолчанию из правил, 0.6)")
+	fs.Float64Var(&opts.PossibleRatio, "possible-ratio", opts.PossibleRatio, "уверенность, с которой ПДн в колонке возможны (по умолчанию из правил, 0.2)")
+	fs.IntVar(&opts.BatchSize, "batch-size", opts.BatchSize, "количество записей между сбросами отчета на диск")
+	fs.BoolVar(&opts.AllDatabases, "all-databases", false, "проверить все пользовательские БД сервера")
+	fs.Var((*listFlag)(&opts.IncludeDatabases), "include-db", "маски БД для проверки при --all-databases, через запятую")
+	fs.Var((*listFlag)(&opts.ExcludeDatabases), "exclude-db", "маски БД, исключаемых при --all-databases, через запятую")
+	fs.Var((*listFlag)(&opts.IncludeSchemas), "include-schema", "маски схем для проверки, через запятую")
+	fs.Var((*listFlag)(&opts.ExcludeSchemas), "exclude-schema", "маски схем, исключаемых из проверки, через запятую")
+	fs.Var((*listFlag)(&opts.IncludeTables), "include-table", "маски таблиц (table или schema.table) для проверки, через запятую")
+	fs.Var((*listFlag)(&opts.ExcludeTables), "exclude-table", "маски таблиц (table или schema.table), исключаемых из проверки, через запятую")
+	fs.BoolVar(&opts.NonInteractive, "non-interactive", false, "не запрашивать недостающие параметры, а завершаться с ошибкой")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("неизвестные аргументы: %s", strings.Join(fs.Args(), " "))
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	// Значение сохраняется, если оно задано флагом или переменной окружения
+	keep := func(flagName, env string) bool {
+		return explicit[flagName] || (env != "" && os.Getenv(env) != "")
+	}
+
+	if opts.ConfigFile != "" {
+		cfg, err := loadConfig(opts.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := cfg.profile(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Defaults.apply(opts, keep)
+		profile.apply(opts, keep)
+	} else if opts.Profile != "" {
+		return nil, errors.New("профиль указан без файла конфигурации (--config)")
+	}
+
+	opts.applyEnv(explicit)
+
+	// Путь к файлу SQLite в --database выбирает СУБД без явного --driver
+	if !keep("driver", "PDN_DRIVER") && opts.Driver == defaultOptions().Driver && isSQLiteFile(opts.Database) {
+		opts.Driver = "sqlite"
+	}
+
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	rules, err := loadRules(opts.RulesFile, Thresholds{Confirmed: opts.ConfirmedRatio, Possible: opts.PossibleRatio})
+	if err != nil {
+		return nil, err
+	}
+	opts.rules = rules
+
+	// Пароль намеренно не принимается флагом, чтобы не попадать в список процессов
+	if err := opts.resolveCredentials(); err != nil {
+		return nil, err
+	}
+
+	if opts.ScanFiles != "" {
+		if opts.Inventory != "" {
+			return nil, errors.New("--scan-files и --inventory нельзя указывать вместе")
+		}
+		if opts.Output == "" {
+			opts.Output = "report_files.csv"
+		}
+		return opts, nil
+	}
+
+	// Для списка серверов параметры подключения берутся из файла
+	if opts.Inventory != "" {
+		if opts.Output == "" {
+			opts.Output = "report_inventory.csv"
+		}
+		return opts, nil
+	}
+
+	if err := opts.promptMissing(); err != nil {
+		return nil, err
+	}
+
+	if opts.Output == "" {
+		database := opts.Database
+		if opts.AllDatabases {
+			database = "all"
+		}
+		if opts.isLocal() {
+			base := filepath.Base(opts.Database)
+			opts.Output = fmt.Sprintf("report_%s_%s.csv", opts.backend.Name(), strings.TrimSuffix(base, filepath.Ext(base)))
+		} else {
+			opts.Output = fmt.Sprintf("report_%s_%s.csv", strings.ReplaceAll(opts.Server, "\\", "_"), database)
+		}
+	}
+
+	return opts, nil
+}
+
+func (o *Options) applyEnv(explicit map[string]bool) {
+	vars := []struct {
+		flag  string
+		env   string
+		value *string
+	}{
+		{"driver", "PDN_DRIVER", &o.Driver},
+		{"server", "PDN_SERVER", &o.Server},
+		{"port", "PDN_PORT", &o.Port},
+		{"database", "PDN_DATABASE", &o.Database},
+		{"user", "PDN_USER", &o.User},
+		{"password-file", "PDN_PASSWORD_FILE", &o.PasswordFile},
+		{"credentials-file", "PDN_CREDENTIALS_FILE", &o.CredentialsFile},
+		{"auth", "PDN_AUTH", &o.Auth.Mode},
+		{"keytab", "PDN_KEYTAB", &o.Auth.Keytab},
+		{"aad-client-id", "PDN_AAD_CLIENT_ID", &o.Auth.AADClientID},
+		{"aad-tenant-id", "PDN_AAD_TENANT_ID", &o.Auth.AADTenantID},
+		{"output", "PDN_OUTPUT", &o.Output},
+		{"s3-endpoint", "PDN_S3_ENDPOINT", &o.S3Endpoint},
+		{"rules", "PDN_RULES", &o.RulesFile},
+	}
+	for _, v := range vars {
+		if explicit[v.flag] {
+			continue
+		}
+		if val := os.Getenv(v.env); val != "" {
+			*v.value = val
+		}
+	}
+}
+
+func (o *Options) validate() error {
+	if err := o.resolveDialect(); err != nil {
+		return err
+	}
+
+	switch {
+	case o.SampleSize <= 0:
+		return errors.New("размер выборки должен быть больше нуля")
+	case o.ConfirmedRatio < 0 || o.PossibleRatio < 0:
+		return errors.New("пороги уверенности не могут быть отрицательными")
+	case o.BatchSize <= 0:
+		return errors.New("размер пакета записи должен быть больше нуля")
+	case o.Parallel <= 0:
+		return errors.New("количество параллельных проверок должно быть больше нуля")
+	case o.MaxOpenConns <= 0:
+		return errors.New("максимум открытых соединений должен быть больше нуля")
+	case o.ConnectTimeout <= 0 || o.ListTimeout <= 0 || o.TableTimeout <= 0 || o.ColumnTimeout <= 0:
+		return errors.New("таймауты должны быть больше нуля")
+	}
+	return nil
+}
+
+// resolveDialect выбирает диалект по имени СУБД, подставляет порт по
+// умолчанию (кроме именованного экземпляра SQL Server) и проверяет
+// совместимость параметров подключения.
+func (o *Options) resolveDialect() error {
+	d, err := dialectByName(o.Driver)
+	if err != nil {
+		return err
+	}
+	o.backend = d
+	o.dialect, _ = d.(Dialect)
+	if o.Port == "" && !o.namedInstance() {
+		o.Port = d.DefaultPort()
+		o.defaultPort = true
+	}
+	return d.Validate(o)
+}
+
+// namedInstance сообщает, что указан именованный экземпляр SQL Server
+// (host\INSTANCE): его порт без --port определяет служба SQL Browser.
+func (o *Options) namedInstance() bool {
+	_, mssql := o.backend.(mssqlDialect)
+	return mssql && strings.Contains(o.Server, "\\")
+}
+
+// isLocal сообщает, что проверяется локальный файл БД, а не сервер.
+func (o *Options) isLocal() bool {
+	return o.backend != nil && isLocalDialect(o.backend)
+}
+
+func (o *Options) promptMissing() error {
+	fields := []struct {
+		value  *string
+		name   string
+		prompt string
+	}{
+		{&o.Server, "сервер (--server)", "Введите сервер БД: "},
+		{&o.Port, "порт (--port)", "Введите порт БД: "},
+		{&o.Database, "имя БД (--database)", "Введите имя БД: "},
+		{&o.User, "логин (--user)", "Введите логин: "},
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if o.isLocal() && (f.value == &o.Server || f.value == &o.Port) {
+			continue
+		}
+		// Сервер мог быть введен только что: порт по умолчанию не подходит
+		// именованному экземпляру
+		if f.value == &o.Port && o.namedInstance() {
+			if o.defaultPort {
+				o.Port = ""
+			}
+			continue
+		}
+		if *f.value != "" || (f.value == &o.Database && o.AllDatabases) || (f.value == &o.User && !o.needsUser()) {
+			continue
+		}
+		if o.NonInteractive {
+			missing = append(missing, f.name)
+			continue
+		}
+		fmt.Print(f.prompt)
+		fmt.Scanln(f.value)
+	}
+
+	if o.Password == "" && o.needsPassword() {
+		if o.NonInteractive {
+			missing = append(missing, "пароль (PDN_PASSWORD, --password-file, --password-stdin, --credentials-file)")
+		} else {
+			password, err := readPassword("Введите пароль: ")
+			if err != nil {
+				return fmt.Errorf("чтение пароля: %v", err)
+			}
+			o.Password = password
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New("не заданы параметры: " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// listFlag — флаг со списком значений через запятую; может повторяться.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/microsoft/go-mssqldb"
 )
 
 type TableInfo struct {
@@ -25,138 +27,149 @@ type ColumnInfo struct {
 	DataType   string
 }
 
-type ValuePattern struct {
-	Value   string
-	Pattern string
-}
-
 type PDNResult struct {
-	DatabaseName string
-	SchemaName   string
-	TableName    string
-	TableType    string
-	ColumnName   string
-	FoundIn      string
-	SampleValue  string
-	Pattern      string
-	PDNType      string
+	ServerName         string
+	ConnectionSecurity string
+	DatabaseName       string
+	SchemaName         string
+	TableName          string
+	TableType          string
+	ColumnName         string
+	FoundIn            string
+	SampleValue        string
+	Pattern            string
+	PDNType            string
+	Category           string
+	// Validation — результат проверки значений (контрольные числа и т.п.)
+	Validation string
+	// Verdict — вердикт по уверенности: verdictConfirmed, verdictPossible
+	// или verdictNone; пуст для ошибок и таймаутов
+	Verdict string
+	// Confidence — уверенность в типе ПДн от 0 до 1, Explanation — из
+	// каких признаков она сложилась
+	Confidence  float64
+	Explanation string
+	// MatchRatio — доля непустых значений выборки, совпавших с типом ПДн;
+	// SampleSize — число непустых значений в выборке
+	MatchRatio float64
+	SampleSize int
+
+	// rule — правило, по которому найден тип ПДн
+	rule *Rule
+	// values — непустые значения выборки колонки (см. applyAccountKeys)
+	values []string
 }
 
 func main() {
-	server, port, database, username, password := getConnectionParams()
-	db := connectToDB(server, port, database, username, password)
-	defer db.Close()
-
-	tables := getTablesAndViews(db)
-	fmt.Printf("\nНайдено %d таблиц/представлений для анализа\n", len(tables))
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal("Ошибка параметров запуска: ", err)
+	}
 
 	resultsChan := make(chan PDNResult, 1000)
 	doneChan := make(chan bool)
 
-	// Генерируем имя файла с сервером и базой
-	reportFileName := fmt.Sprintf("report_%s_%s.csv", strings.ReplaceAll(server, "\\", "_"), database)
-
 	go func() {
-		err := saveResultsToCSVBatches(server, reportFileName, resultsChan)
+		// При проверке списка серверов защита соединения каждого сервера
+		// записывается в файл статусов, у файлов выгрузок ее нет
+		withSecurity := opts.Inventory == "" && opts.ScanFiles == ""
+		err := saveResultsToCSVBatches(opts.Output, opts.BatchSize, withSecurity, resultsChan)
 		if err != nil {
 			log.Fatal("Ошибка сохранения в CSV:", err)
 		}
 		doneChan <- true
 	}()
 
-	analyzeTablesWithBatches(db, database, tables, resultsChan)
+	var statuses []ServerStatus
+	// Сбои отдельных БД не прерывают запись отчета по остальным
+	var dbErrs *databaseErrors
+	if opts.ScanFiles != "" {
+		if err := scanFiles(opts, resultsChan); err != nil {
+			log.Fatal(err)
+		}
+	} else if opts.Inventory != "" {
+		inv, err := loadInventory(opts.Inventory)
+		if err != nil {
+			log.Fatal(err)
+		}
+		statuses = scanInventory(opts, inv, resultsChan)
+	} else if err := scanServer(opts, resultsChan); err != nil && !errors.As(err, &dbErrs) {
+		log.Fatal(err)
+	}
 
 	close(resultsChan)
 	<-doneChan
 
-	fmt.Printf("\nОтчет успешно сохранен в %s\n", reportFileName)
-}
+	fmt.Printf("\nОтчет успешно сохранен в %s\n", opts.Output)
+	if dbErrs != nil {
+		log.Fatal(dbErrs)
+	}
 
-func getConnectionParams() (string, string, string, string, string) {
-	var server, port, database, username, password string
-
-	fmt.Print("Введите сервер БД: ")
-	fmt.Scanln(&server)
-	fmt.Print("Введите порт БД: ")
-	fmt.Scanln(&port)
-	fmt.Print("Введите имя БД: ")
-	fmt.Scanln(&database)
-	fmt.Print("Введите логин: ")
-	fmt.Scanln(&username)
-	fmt.Print("Введите пароль: ")
-	fmt.Scanln(&password)
-
-	return server, port, database, username, password
-}
+	if statuses != nil {
+		fmt.Println("\nСтатус проверки серверов:")
+		failed := 0
+		for _, s := range statuses {
+			fmt.Printf("  %-30s %-8s записей: %-6d %s\n", s.Name, s.Status, s.Records, s.Error)
+			if s.Error != "" {
+				failed++
+			}
+		}
 
-func connectToDB(server, port, database, username, password string) *sql.DB {
-	connString := fmt.Sprintf("server=%s;port=%s;database=%s;user id=%s;password=%s",
-		server, port, database, username, password)
+		statusFile := statusFileName(opts.Output)
+		if err := saveServerStatuses(statusFile, statuses); err != nil {
+			log.Fatal("Ошибка сохранения статусов серверов:", err)
+		}
+		fmt.Printf("Статусы серверов сохранены в %s\n", statusFile)
 
-	db, err := sql.Open("sqlserver", connString)
+		if failed > 0 {
+			log.Fatalf("Не удалось проверить серверов: %d из %d", failed, len(statuses))
+		}
+	}
+}
+
+func connectToDB(opts *Options, database string) (*sql.DB, error) {
+	db, err := sql.Open(opts.dialect.DriverName(opts), opts.dialect.DSN(opts, database))
 	if err != nil {
-		log.Fatal("Ошибка подключения:", err)
+		return nil, fmt.Errorf("ошибка подключения: %v", err)
 	}
 
-	db.SetConnMaxLifetime(15 * time.Minute)
-	db.SetMaxOpenConns(5)
-	db.SetMaxIdleConns(2)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ConnectTimeout)
 	defer cancel()
 	if err := db.PingContext(ctx); err != nil {
-		log.Fatal("Ошибка проверки подключения:", err)
+		db.Close()
+		return nil, fmt.Errorf("ошибка проверки подключения: %v", err)
 	}
 
-	fmt.Println("✓ Успешное подключение к БД")
-	return db
+	fmt.Printf("✓ Успешное подключение к БД %s\n", database)
+	return db, nil
 }
 
-func getTablesAndViews(db *sql.DB) []TableInfo {
+func getTablesAndViews(db *sql.DB, dialect Dialect, timeout time.Duration) ([]TableInfo, error) {
 	fmt.Println("\nПолучение списка таблиц и представлений...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	query := `
-		SELECT s.name AS schema_name, t.name AS table_name, t.type_desc AS table_type
-		FROM sys.tables t
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		UNION ALL
-		SELECT s.name AS schema_name, v.name AS view_name, 'VIEW' AS table_type
-		FROM sys.views v
-		INNER JOIN sys.schemas s ON v.schema_id = s.schema_id
-	`
-
-	rows, err := db.QueryContext(ctx, query)
-	if err != nil {
-		log.Fatal("Ошибка получения таблиц:", err)
-	}
-	defer rows.Close()
-
-	var tables []TableInfo
-	for rows.Next() {
-		var ti TableInfo
-		if err := rows.Scan(&ti.SchemaName, &ti.TableName, &ti.TableType); err != nil {
-			log.Println("Ошибка чтения данных таблицы:", err)
-			continue
-		}
-		tables = append(tables, ti)
-	}
-
-	return tables
+	return dialect.ListObjects(ctx, db)
 }
 
-func analyzeTablesWithBatches(db *sql.DB, database string, tables []TableInfo, resultsChan chan<- PDNResult) {
+func analyzeTablesWithBatches(db *sql.DB, opts *Options, database string, tables []TableInfo, resultsChan chan<- PDNResult) {
 	totalTables := len(tables)
 
 	for i, table := range tables {
 		fmt.Printf("\n[%d/%d] Анализ %s.%s (%s)...\n",
 			i+1, totalTables, table.SchemaName, table.TableName, table.TableType)
 
-		tableCtx, tableCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		tableCtx, tableCancel := context.WithTimeout(context.Background(), opts.TableTimeout)
 
-		columns, err := getColumns(tableCtx, db, table.SchemaName, table.TableName)
+		columns, err := opts.dialect.ListColumns(tableCtx, db, table)
 		if err != nil {
 			log.Printf("⚠ Ошибка получения колонок: %v - пропускаем\n", err)
 			tableCancel()
@@ -175,10 +188,10 @@ func analyzeTablesWithBatches(db *sql.DB, database string, tables []TableInfo, r
 
 		for _, column := range columns {
 			go func(col ColumnInfo) {
-				ctx, cancel := context.WithTimeout(tableCtx, 60*time.Second)
+				ctx, cancel := context.WithTimeout(tableCtx, opts.ColumnTimeout)
 				defer cancel()
 
-				res, err := analyzeColumn(ctx, db, database, table, col)
+				res, err := analyzeColumn(ctx, db, opts, database, table, col)
 				if err != nil {
 					errorChan <- err
 					columnResultsChan <- nil
@@ -193,12 +206,9 @@ func analyzeTablesWithBatches(db *sql.DB, database string, tables []TableInfo, r
 		for range columns {
 			select {
 			case res := <-columnResultsChan:
-				if res != nil {
-					allTableResults = append(allTableResults, res...)
-					for _, r := range res {
-						resultsChan <- r
-						processedColumns[r.ColumnName] = true
-					}
+				allTableResults = append(allTableResults, res...)
+				for _, r := range res {
+					processedColumns[r.ColumnName] = true
 				}
 			case <-tableCtx.Done():
 				fmt.Printf("  ⚠ Превышено время обработки таблицы %s.%s\n",
@@ -206,33 +216,7 @@ func analyzeTablesWithBatches(db *sql.DB, database string, tables []TableInfo, r
 			}
 		}
 
-		hasOtherPersonalData := false
-		for _, res := range allTableResults {
-			if res.PDNType != "Адрес" && res.PDNType != "Нет" && res.PDNType != "Не обработано" {
-				hasOtherPersonalData = true
-				break
-			}
-		}
-
-		if !hasOtherPersonalData {
-			for i, res := range allTableResults {
-				if res.PDNType == "Адрес" {
-					allTableResults[i].PDNType = "Нет"
-				}
-			}
-		}
-
-		fmt.Println("  Итоги по таблице:")
-		hasPDN := false
-		for _, res := range allTableResults {
-			if res.PDNType != "Нет" && res.PDNType != "Не обработано" {
-				fmt.Printf("    * %s: %s (%s)\n", res.ColumnName, res.PDNType, res.FoundIn)
-				hasPDN = true
-			}
-		}
-		if !hasPDN {
-			fmt.Println("    * Персональные данные не обнаружены")
-		}
+		finishTable(opts.rules, allTableResults, resultsChan)
 
 		for _, column := range columns {
 			if !processedColumns[column.ColumnName] {
@@ -254,6 +238,60 @@ func analyzeTablesWithBatches(db *sql.DB, database string, tables []TableInfo, r
 	}
 }
 
+// finishTable уточняет результаты таблицы по другим ее колонкам (см.
+// applyAccountKeys и applyTableContext), отправляет их в отчет и выводит
+// итоги.
+func finishTable(rules *RuleSet, tableResults []PDNResult, resultsChan chan<- PDNResult) {
+	applyAccountKeys(rules, tableResults)
+	applyTableContext(rules, tableResults)
+	for _, r := range tableResults {
+		resultsChan <- r
+	}
+	printTableSummary(tableResults)
+}
+
+// printTableSummary выводит найденные в таблице ПДн. Адрес без других ПДн
+// в той же таблице персональными данными не считается.
+func printTableSummary(tableResults []PDNResult) {
+	hasOtherPersonalData := false
+	for _, res := range tableResults {
+		if res.PDNType != "Адрес" && isPDNResult(res) {
+			hasOtherPersonalData = true
+			break
+		}
+	}
+
+	fmt.Println("  Итоги по таблице:")
+	hasPDN := false
+	for _, res := range tableResults {
+		if res.PDNType == "Адрес" && !hasOtherPersonalData {
+			continue
+		}
+		if isPDNResult(res) {
+			fmt.Printf("    * %s: %s (%s)\n", res.ColumnName, res.PDNType, describeFinding(res))
+			hasPDN = true
+		}
+	}
+	if !hasPDN {
+		fmt.Println("    * Персональные данные не обнаружены")
+	}
+}
+
+// describeFinding поясняет, где найден тип ПДн и с какой уверенностью.
+func describeFinding(res PDNResult) string {
+	desc := fmt.Sprintf("%s, уверенность %.2f", res.FoundIn, res.Confidence)
+	if res.Verdict == verdictPossible {
+		desc += ", возможно"
+	}
+	return desc
+}
+
+// isPDNResult сообщает, что результат указывает на ПДн: уверенность в типе
+// ПДн достигла хотя бы порога possible.
+func isPDNResult(res PDNResult) bool {
+	return res.Verdict == verdictConfirmed || res.Verdict == verdictPossible
+}
+
 func createTableTimeoutResult(database string, table TableInfo) PDNResult {
 	return PDNResult{
 		DatabaseName: database,
@@ -268,45 +306,13 @@ func createTableTimeoutResult(database string, table TableInfo) PDNResult {
 	}
 }
 
-func getColumns(ctx context.Context, db *sql.DB, schemaName, tableName string) ([]ColumnInfo, error) {
-	query := `
-		SELECT c.name AS column_name, tp.name AS data_type
-		FROM sys.columns c
-		JOIN sys.objects o ON c.object_id = o.object_id
-		JOIN sys.schemas s ON o.schema_id = s.schema_id
-		JOIN sys.types tp ON c.user_type_id = tp.user_type_id
-		WHERE s.name = @schema AND o.name = @table
-	`
-
-	rows, err := db.QueryContext(ctx, query,
-		sql.Named("schema", schemaName),
-		sql.Named("table", tableName))
-	if err != nil {
-		return nil, fmt.Errorf("запрос колонок: %v", err)
-	}
-	defer rows.Close()
-
-	var columns []ColumnInfo
-	for rows.Next() {
-		var ci ColumnInfo
-		if err := rows.Scan(&ci.ColumnName, &ci.DataType); err != nil {
-			return nil, fmt.Errorf("чтение колонки: %v", err)
-		}
-		columns = append(columns, ci)
-	}
-
-	return columns, nil
-}
-
-func analyzeColumn(ctx context.Context, db *sql.DB, database string, table TableInfo, column ColumnInfo) ([]PDNResult, error) {
-	var results []PDNResult
-
-	values, err := getSampleValues(ctx, db, table.SchemaName, table.TableName, column.ColumnName)
+func analyzeColumn(ctx context.Context, db *sql.DB, opts *Options, database string, table TableInfo, column ColumnInfo) ([]PDNResult, error) {
+	values, err := opts.dialect.SampleValues(ctx, db, table, column, opts.SampleSize)
 	if err != nil {
 		log.Printf("  Ошибка получения значений для %s.%s (%s): %v",
 			table.TableName, column.ColumnName, column.DataType, err)
 
-		results = append(results, PDNResult{
+		return []PDNResult{{
 			DatabaseName: database,
 			SchemaName:   table.SchemaName,
 			TableName:    table.TableName,
@@ -316,234 +322,126 @@ func analyzeColumn(ctx context.Context, db *sql.DB, database string, table Table
 			SampleValue:  "N/A",
 			Pattern:      fmt.Sprintf("Ошибка получения значений: %v", err),
 			PDNType:      "Не обработано",
-		})
-
-		return results, nil
-	}
-
-	sampleValue := "N/A"
-	if len(values) > 0 {
-		sampleValue = values[0].Value
+		}}, nil
 	}
 
-	pdnTypes := checkForPDNPatterns(column.ColumnName)
-	if len(pdnTypes) > 0 {
-		for _, pdnType := range pdnTypes {
-			res := PDNResult{
-				DatabaseName: database,
-				SchemaName:   table.SchemaName,
-				TableName:    table.TableName,
-				TableType:    table.TableType,
-				ColumnName:   column.ColumnName,
-				FoundIn:      "header",
-				SampleValue:  sampleValue,
-				PDNType:      pdnType,
-			}
-			if len(values) > 0 {
-				res.Pattern = values[0].Pattern
-			}
-			results = append(results, res)
-		}
-	}
+	return classifyColumn(opts.rules, database, table, column, values), nil
+}
 
-	var valuePdnTypes []string
+// classifyColumn ищет признаки ПДн в имени колонки и в выбранных значениях
+// по правилам rules. Используется всеми источниками, в том числе не
+// реляционными. Для каждого типа ПДн с признаками возвращается одна строка
+// с уверенностью (см. evidence.score) и вердиктом по порогам правила.
+func classifyColumn(rules *RuleSet, database string, table TableInfo, column ColumnInfo, values []string) []PDNResult {
+	var sample []string
 	for _, val := range values {
-		if types := checkForPDNPatterns(val.Value); len(types) > 0 {
-			valuePdnTypes = appendIfNotExists(valuePdnTypes, types...)
-			for _, pdnType := range types {
-				results = append(results, PDNResult{
-					DatabaseName: database,
-					SchemaName:   table.SchemaName,
-					TableName:    table.TableName,
-					TableType:    table.TableType,
-					ColumnName:   column.ColumnName,
-					FoundIn:      "value",
-					SampleValue:  val.Value,
-					Pattern:      val.Pattern,
-					PDNType:      pdnType,
-				})
-			}
+		if strings.TrimSpace(val) != "" {
+			sample = append(sample, val)
 		}
 	}
 
-	if len(pdnTypes) == 0 && len(valuePdnTypes) == 0 {
-		res := PDNResult{
-			DatabaseName: database,
-			SchemaName:   table.SchemaName,
-			TableName:    table.TableName,
-			TableType:    table.TableType,
-			ColumnName:   column.ColumnName,
-			FoundIn:      "none",
-			SampleValue:  sampleValue,
-			PDNType:      "Нет",
-		}
-		if len(values) > 0 {
-			res.Pattern = values[0].Pattern
-		}
-		results = append(results, res)
+	base := PDNResult{
+		DatabaseName: database,
+		SchemaName:   table.SchemaName,
+		TableName:    table.TableName,
+		TableType:    table.TableType,
+		ColumnName:   column.ColumnName,
+		SampleValue:  "N/A",
+		SampleSize:   len(sample),
+		values:       sample,
 	}
-
-	return results, nil
-}
-
-func getSampleValues(ctx context.Context, db *sql.DB, schemaName, tableName, columnName string) ([]ValuePattern, error) {
-	// Пытаемся получить значения как строку
-	query := fmt.Sprintf(`
-		SELECT TOP 5 TRY_CAST([%s] AS NVARCHAR(MAX)) AS sample_value
-		FROM [%s].[%s] WITH (NOLOCK)
-		WHERE [%s] IS NOT NULL AND TRY_CAST([%s] AS NVARCHAR(MAX)) != ''
-	`, columnName, schemaName, tableName, columnName, columnName)
-
-	rows, err := db.QueryContext(ctx, query)
-	if err != nil {
-		// Если ошибка, пробуем альтернативный вариант с CONVERT
-		query = fmt.Sprintf(`
-			SELECT TOP 5 CONVERT(NVARCHAR(MAX), [%s]) AS sample_value
-			FROM [%s].[%s] WITH (NOLOCK)
-			WHERE [%s] IS NOT NULL AND CONVERT(NVARCHAR(MAX), [%s]) != ''
-		`, columnName, schemaName, tableName, columnName, columnName)
-
-		rows, err = db.QueryContext(ctx, query)
-		if err != nil {
-			return nil, fmt.Errorf("запрос значений: %v", err)
-		}
+	if len(sample) > 0 {
+		base.SampleValue = sample[0]
+		base.Pattern = getValuePattern(sample[0])
 	}
-	defer rows.Close()
 
-	var values []string
-	for rows.Next() {
-		var val string
-		if err := rows.Scan(&val); err != nil {
-			return nil, fmt.Errorf("чтение значения: %v", err)
+	found := make(map[*Rule]*evidence)
+	get := func(rule *Rule) *evidence {
+		e := found[rule]
+		if e == nil {
+			e = &evidence{sampleSize: len(sample)}
+			found[rule] = e
 		}
-		values = append(values, val)
+		return e
 	}
 
-	// Если нет значений, проверяем, есть ли вообще данные в колонке
-	if len(values) == 0 {
-		checkQuery := fmt.Sprintf(`
-			SELECT TOP 1 1 
-			FROM [%s].[%s] WITH (NOLOCK)
-			WHERE [%s] IS NOT NULL AND 
-				  (TRY_CAST([%s] AS NVARCHAR(MAX)) IS NOT NULL AND 
-				   TRY_CAST([%s] AS NVARCHAR(MAX)) != '')
-		`, schemaName, tableName, columnName, columnName, columnName)
+	for _, hm := range rules.matchHeader(column.ColumnName) {
+		get(hm.rule).header = &hm
+	}
 
-		var exists int
-		err := db.QueryRowContext(ctx, checkQuery).Scan(&exists)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return nil, nil // Колонка пустая или содержит только NULL/пустые значения
+	// Пример значения берется из первого совпавшего значения, а если
+	// совпадений нет — из первого отклоненного
+	examples := make(map[*Rule]string)
+	excluded := rules.excludedByName(column.ColumnName)
+	for _, val := range sample {
+		for _, m := range rules.matchValue(val) {
+			if excluded[m.rule] {
+				continue
 			}
-
-			// Пробуем альтернативный вариант проверки
-			checkQuery = fmt.Sprintf(`
-				SELECT TOP 1 1 
-				FROM [%s].[%s] WITH (NOLOCK)
-				WHERE [%s] IS NOT NULL
-			`, schemaName, tableName, columnName)
-
-			err = db.QueryRowContext(ctx, checkQuery).Scan(&exists)
-			if err != nil {
-				if err == sql.ErrNoRows {
-					return nil, nil
+			e := get(m.rule)
+			if m.rejected != "" {
+				if e.rejected == 0 {
+					e.reason = m.rejected
+					if e.matched == 0 {
+						examples[m.rule] = val
+					}
 				}
-				return nil, fmt.Errorf("проверка наличия данных: %v", err)
+				e.rejected++
+				continue
 			}
+			if e.matched == 0 {
+				examples[m.rule] = val
+			}
+			e.addMatch(val, m)
 		}
 	}
 
-	patternMap := make(map[string]string)
-	for _, val := range values {
-		pattern := getValuePattern(val)
-		if _, exists := patternMap[pattern]; !exists {
-			patternMap[pattern] = val
+	var results []PDNResult
+	for _, rule := range rules.Rules {
+		e := found[rule]
+		if e == nil {
+			continue
 		}
-	}
-
-	var result []ValuePattern
-	for pattern, val := range patternMap {
-		result = append(result, ValuePattern{
-			Value:   val,
-			Pattern: pattern,
-		})
-	}
-
-	return result, nil
-}
 
-func checkForPDNPatterns(input string) []string {
-	input = strings.ToLower(input)
-	var foundTypes []string
+		res := base
+		res.rule = rule
+		res.PDNType = rule.Name
+		res.Category = rule.Category
+		res.Confidence, res.Explanation = e.score(rule, column)
+		res.Verdict = rules.thresholds(rule).verdict(res.Confidence)
 
-	valuePatterns := map[string]*regexp.Regexp{
-		"Email":           regexp.MustCompile(`[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`),
-		"Телефон":         regexp.MustCompile(`(\+7|8)[\s\-\(]?\d{3}[\)\s\-]?\d{3}[\s\-]?\d{2}[\s\-]?\d{2}`),
-		"Паспорт РФ":      regexp.MustCompile(`\b\d{2}\s?\d{2}\s?\d{6}\b|(?:паспорт|серия|номер)[^\d]*\d{4}[^\d]*\d{6}`),
-		"СНИЛС":           regexp.MustCompile(`\b\d{3}[-]?\d{3}[-]?\d{3}[-\s]?\d{2}\b`),
-		"Кредитная карта": regexp.MustCompile(`\d{4}[\s\-]?\d{4}[\s\-]?\d{4}[\s\-]?\d{4}`),
-		"Семья":           regexp.MustCompile(`(?i)\b(дочь|сын|мать|отец|брат|сестра|супруг[аи]?|муж|жена|родител[яи]|ребенок|дети|вдова|вдовец)\b`),
-		"Армейка":         regexp.MustCompile(`(?i)\b(вус\s*\d{4,6}|воен\s*учетн\w*\s*специальн\w*|специальн\w*\s*по\s*вус)\b`),
-		"Пол":             regexp.MustCompile(`(?i)\b(муж(ской|чина)?|жен(ский|щина)?|m(ale)?|f(emale)?|м|ж)\b`),
-	}
-
-	headerPatterns := map[string][]string{
-		"ФИО":                 {"фамил", "fami", "surn", "lastname", "last name", "last_name", "имя", "firstname", "first name", "first_name", "отчест", "middlename", "middle name", "middle_name", "patronym", "фам", "fio", "фио", "fullname", "full name"},
-		"Персональные данные": {"контакт", "сотруд", "руковод", "manag", "физи", "физл", "персон", "person", "empl"},
-		"Адрес":               {"адрес", "address", "addr", "location", "место"},
-		"Email":               {"эп", "mail", "адресэп", "адрес эп"},
-		"Телефон":             {"телефон", "phone", "tel", "мобильн", "mobile", "contact"},
-		"Паспорт":             {"паспор", "passpor", "серия", "series"},
-		"СНИЛС/ИНН":           {"снилс", "snils", "инн", "taxid", "tax"},
-		"Дата рождения":       {"рожд", "birth", "dateofbirth", "birthdate", "датарожд", "дата рожд"},
-		"Таб. номер":          {"таб_н", "табель", "табн", "таб.н", "таб н", "таб. н"},
-		"Медицина":            {"медиц", "болез", "больн"},
-		"Образование":         {"школ", "аттест", "вуз"},
-		"Семья":               {"доч", "сын", "мать", "отец"},
-		"Фото":                {"фото", "foto", "photo"},
-		"Пол":                 {"gend", "пол", "sex"},
-	}
-
-	// Проверка ИНН физлица (12 цифр, не начинается с 000)
-	if matched, _ := regexp.MatchString(`(^|\D)\d{12}($|\D)`, input); matched {
-		if !strings.HasPrefix(strings.TrimLeft(input, "0123456789"), "000") {
-			foundTypes = appendIfNotExists(foundTypes, "ИНН физлица")
-		}
-	}
-
-	// Проверка номеров паспортов (исключаем начинающиеся с 000)
-	if matched, _ := regexp.MatchString(`\b\d{2}\s?\d{2}\s?\d{6}\b`, input); matched {
-		parts := regexp.MustCompile(`\s?`).Split(input, -1)
-		if len(parts) == 3 && !strings.HasPrefix(parts[0]+parts[1], "0000") {
-			foundTypes = appendIfNotExists(foundTypes, "Паспорт РФ")
+		switch {
+		case e.header != nil && e.matched+e.rejected > 0:
+			res.FoundIn = "header+value"
+		case e.header != nil:
+			res.FoundIn = "header"
+		default:
+			res.FoundIn = "value"
 		}
-	}
-
-	// Остальные проверки по регулярным выражениям
-	for pdnType, re := range valuePatterns {
-		if re.MatchString(input) {
-			foundTypes = appendIfNotExists(foundTypes, pdnType)
+		if e.matched+e.rejected > 0 {
+			res.SampleValue = examples[rule]
+			res.Pattern = getValuePattern(res.SampleValue)
+			res.MatchRatio = e.ratio()
 		}
-	}
 
-	// Проверка по ключевым словам в заголовке
-	for pdnType, keywords := range headerPatterns {
-		for _, keyword := range keywords {
-			if strings.Contains(input, keyword) {
-				foundTypes = appendIfNotExists(foundTypes, pdnType)
-			}
+		switch {
+		case e.matched > 0:
+			res.Validation = e.validation()
+		case e.rejected > 0:
+			res.Validation = "значения не прошли проверку: " + e.reason
 		}
+		results = append(results, res)
 	}
 
-	if containsAny(input, []string{"ул.", "улица", "дом", "кв.", "квартира"}) {
-		foundTypes = appendIfNotExists(foundTypes, "Адрес")
-	}
-
-	if containsAny(input, []string{"рожден", "birthday"}) {
-		foundTypes = appendIfNotExists(foundTypes, "Дата рождения")
+	if len(results) == 0 {
+		res := base
+		res.FoundIn = "none"
+		res.PDNType = "Нет"
+		res.Verdict = verdictNone
+		results = append(results, res)
 	}
 
-	return foundTypes
+	return results
 }
 
 func maskSensitiveData(value string) string {
@@ -565,15 +463,6 @@ func containsAny(s string, substrings []string) bool {
 	return false
 }
 
-func appendIfNotExists(slice []string, items ...string) []string {
-	for _, item := range items {
-		if !contains(slice, item) {
-			slice = append(slice, item)
-		}
-	}
-	return slice
-}
-
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -598,7 +487,7 @@ func getValuePattern(value string) string {
 	return string(pattern)
 }
 
-func saveResultsToCSVBatches(server, fileName string, resultsChan <-chan PDNResult) error {
+func saveResultsToCSVBatches(fileName string, batchSize int, withSecurity bool, resultsChan <-chan PDNResult) error {
 	file, err := os.Create(fileName)
 	if err != nil {
 		return err
@@ -619,22 +508,42 @@ func saveResultsToCSVBatches(server, fileName string, resultsChan <-chan PDNResu
 		"Тип ПДн",
 		"Пример значения",
 		"Пример значения с маскированием",
+		"Категория ПДн",
+		"Проверка значений",
+		"Доля совпадений",
+		"Размер выборки",
+		"Уверенность",
+		"Обоснование",
+	}
+	// Защита соединения одна на весь запуск, но остается обычной колонкой,
+	// чтобы отчет читался как таблица
+	if withSecurity {
+		header = append(header, "Защита соединения")
 	}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
 
-	batchSize := 100
 	batchCount := 0
 
 	for result := range resultsChan {
 		hasPDN := "Да"
-		if result.PDNType == "Нет" || result.PDNType == "Не обработано" {
+		if !isPDNResult(result) {
 			hasPDN = "Нет"
+		} else if result.Verdict == verdictPossible {
+			hasPDN = "Возможно"
+		}
+
+		var ratio, confidence string
+		if strings.Contains(result.FoundIn, "value") {
+			ratio = strconv.FormatFloat(result.MatchRatio, 'f', 2, 64)
+		}
+		if result.Verdict != "" && result.FoundIn != "none" {
+			confidence = strconv.FormatFloat(result.Confidence, 'f', 2, 64)
 		}
 
 		record := []string{
-			server,
+			result.ServerName,
 			result.DatabaseName,
 			result.SchemaName,
 			result.TableName,
@@ -644,6 +553,15 @@ func saveResultsToCSVBatches(server, fileName string, resultsChan <-chan PDNResu
 			result.PDNType,
 			result.SampleValue,
 			maskSensitiveData(result.SampleValue),
+			result.Category,
+			result.Validation,
+			ratio,
+			strconv.Itoa(result.SampleSize),
+			confidence,
+			result.Explanation,
+		}
+		if withSecurity {
+			record = append(record, result.ConnectionSecurity)
 		}
 
 		if err := writer.Write(record); err != nil {
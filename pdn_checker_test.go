@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSample возвращает выборку из total значений, первые matched из
+// которых получены из format, остальные не похожи на ПДн.
+func testSample(format string, matched, total int) []string {
+	values := make([]string, total)
+	for i := range values {
+		if i < matched {
+			values[i] = fmt.Sprintf(format, i)
+		} else {
+			values[i] = "нет данных"
+		}
+	}
+	return values
+}
+
+// testVerdict возвращает вердикт классификации колонки по типу ПДн pdnType.
+func testVerdict(t *testing.T, rules *RuleSet, column ColumnInfo, values []string, pdnType string) (string, float64) {
+	t.Helper()
+	for _, res := range classifyColumn(rules, "db", TableInfo{TableName: "t"}, column, values) {
+		if res.PDNType == pdnType {
+			return res.Verdict, res.Confidence
+		}
+	}
+	return "", 0
+}
+
+// TestClassifyThresholds проверяет вердикты по порогам confirmed (0.6) и
+// possible (0.2): для типа, найденного только в значениях, уверенность
+// равна доле совпавших значений.
+func TestClassifyThresholds(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := ColumnInfo{ColumnName: "c1", DataType: "varchar"}
+
+	tests := []struct {
+		matched int
+		verdict string
+	}{
+		{13, verdictConfirmed},
+		{12, verdictConfirmed},
+		{11, verdictPossible},
+		{5, verdictPossible},
+		{4, verdictPossible},
+		{3, verdictNone},
+	}
+	for _, tt := range tests {
+		values := testSample("user%d@example.com", tt.matched, 20)
+		verdict, confidence := testVerdict(t, rules, column, values, "Email")
+		if verdict != tt.verdict {
+			t.Errorf("%d из 20: вердикт %q (уверенность %.2f), ожидался %q", tt.matched, verdict, confidence, tt.verdict)
+		}
+	}
+
+	// Без совпадений колонка получает одну строку без ПДн
+	results := classifyColumn(rules, "db", TableInfo{TableName: "t"}, column, testSample("", 0, 20))
+	if len(results) != 1 || results[0].PDNType != "Нет" || isPDNResult(results[0]) {
+		t.Errorf("колонка без ПДн: %+v", results)
+	}
+}
+
+// TestClassifyRuleThresholds проверяет, что пороги правила заменяют общие
+// пороги файла правил и флагов, а флаги — общие пороги файла.
+func TestClassifyRuleThresholds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte(`
+thresholds:
+  confirmed: 0.8
+rules:
+  - id: contract
+    name: Номер договора
+    category: общие
+    values: ['дог-\d{6}']
+    types: [text]
+    thresholds:
+      possible: 0.05
+      confirmed: 0.3
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := ColumnInfo{ColumnName: "c1", DataType: "varchar"}
+
+	tests := []struct {
+		name      string
+		format    string
+		pdnType   string
+		matched   int
+		verdict   string
+		withFlags string
+	}{
+		{"ниже possible правила", "дог-%06d", "Номер договора", 0, "", ""},
+		{"possible правила 0.05", "дог-%06d", "Номер договора", 2, verdictPossible, verdictPossible},
+		{"confirmed правила 0.3", "дог-%06d", "Номер договора", 7, verdictConfirmed, verdictConfirmed},
+		{"confirmed файла 0.8", "user%d@example.com", "Email", 13, verdictPossible, verdictConfirmed},
+		{"possible по умолчанию", "user%d@example.com", "Email", 3, verdictNone, verdictNone},
+	}
+	for _, tt := range tests {
+		values := testSample(tt.format, tt.matched, 20)
+		for _, run := range []struct {
+			flags Thresholds
+			want  string
+		}{
+			{Thresholds{}, tt.verdict},
+			{Thresholds{Confirmed: 0.6}, tt.withFlags},
+		} {
+			rules, err := loadRules(path, run.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verdict, confidence := testVerdict(t, rules, column, values, tt.pdnType); verdict != run.want {
+				t.Errorf("%s, флаги %+v: вердикт %q (уверенность %.2f), ожидался %q",
+					tt.name, run.flags, verdict, confidence, run.want)
+			}
+		}
+	}
+}
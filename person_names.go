@@ -0,0 +1,235 @@
+package main
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+// Словари для распознавания ФИО в значениях, см. detectPersonName.
+var (
+	//go:embed dict/surnames.txt
+	surnamesDict string
+	//go:embed dict/first_names.txt
+	firstNamesDict string
+
+	surnames   = wordSet(surnamesDict)
+	firstNames = wordSet(firstNamesDict)
+)
+
+// Веса значений для detectPersonName: часть имени (Иван, Петрова) может
+// оказаться и не ФИО — названием, кличкой, городом.
+const (
+	nameFullWeight = 1.0
+	namePartWeight = 0.5
+)
+
+// surnameEndings — типичные окончания русских фамилий; по ним фамилия
+// узнается, если ее нет в словаре, но рядом есть имя или инициалы.
+var surnameEndings = []string{
+	"ов", "ев", "ин", "ын", "ский", "цкий", "ской", "ых", "их", "енко", "ук", "юк", "ян", "дзе", "швили",
+	"ова", "ева", "ина", "ына", "ская", "цкая",
+}
+
+// feminineSurnameEndings приводят женскую форму фамилии к мужской,
+// в которой фамилии записаны в словаре.
+var feminineSurnameEndings = []struct{ feminine, masculine string }{
+	{"ская", "ский"}, {"цкая", "цкий"},
+	{"ова", "ов"}, {"ева", "ев"}, {"ина", "ин"}, {"ына", "ын"},
+}
+
+// patronymicNames — окончания отчеств и окончания имен, от которых они
+// образованы: Иван — Иванович, Сергей — Сергеевич, Юрий — Юрьевич, Игорь —
+// Игоревич, Илья — Ильич, Кузьма — Кузьминична.
+var patronymicNames = []struct {
+	ending string
+	names  []string
+}{
+	{"ович", []string{""}}, {"овна", []string{""}},
+	{"ьевич", []string{"ий"}}, {"ьевна", []string{"ий"}},
+	{"евич", []string{"й", "ь"}}, {"евна", []string{"й", "ь"}},
+	{"инична", []string{"а", "я"}}, {"ична", []string{"а", "я"}}, {"ич", []string{"а", "я"}},
+}
+
+// patronymicStems — основы отчеств от имен с беглой гласной.
+var patronymicStems = map[string]string{
+	"павл": "павел", "льв": "лев", "михайл": "михаил", "яковл": "яков",
+}
+
+// detectPersonName распознает ФИО по словарям фамилий и имен и окончаниям
+// отчеств: полное ФИО в любом порядке, фамилию с именем или с инициалами
+// (Иванов И.И., И. И. Иванов) и имя с отчеством. Одно слово — имя,
+// фамилия из словаря или отчество от имени из словаря — засчитывается с
+// весом namePartWeight.
+// ФИО латиницей (Ivanov Ivan) проверяется после транслитерации.
+func detectPersonName(value string) (float64, string) {
+	words, initials, ok := splitPersonName(value)
+	if !ok {
+		return 0, ""
+	}
+
+	var first, patronymic, surname, surnameLike int
+	for _, w := range words {
+		switch {
+		case firstNames[w]:
+			first++
+		// Одно слово с окончанием отчества может оказаться и словом вроде
+		// Кирпич или Газпромович, поэтому без соседнего имени отчество
+		// засчитывается, только если образовано от имени из словаря
+		case isPatronymic(w) && (len(words) > 1 || patronymicOfName(w)):
+			patronymic++
+		case isSurname(w):
+			surname++
+		case hasSurnameEnding(w):
+			surnameLike++
+		}
+	}
+
+	switch {
+	case initials > 0:
+		if len(words) == 1 && surname+surnameLike == 1 {
+			return nameFullWeight, "фамилия и инициалы"
+		}
+	case len(words) == 3:
+		// Третье слово должно быть фамилией: «Иван Петрович Ремонт» — не ФИО
+		if first == 1 && patronymic == 1 && surname+surnameLike == 1 {
+			return nameFullWeight, "полное ФИО"
+		}
+	case len(words) == 2:
+		switch {
+		case first == 1 && surname+surnameLike == 1:
+			return nameFullWeight, "фамилия и имя"
+		case first == 1 && patronymic == 1:
+			return nameFullWeight, "имя и отчество"
+		}
+	case len(words) == 1:
+		switch {
+		case first == 1:
+			return namePartWeight, "имя"
+		case surname == 1:
+			return namePartWeight, "фамилия"
+		case patronymic == 1:
+			return namePartWeight, "отчество"
+		}
+	}
+	return 0, ""
+}
+
+// splitPersonName делит значение на слова в нижнем регистре и считает
+// инициалы (И. или слитно И.И.). Возвращает ok = false, если значение не
+// похоже на ФИО: есть цифры или другие знаки, больше трех слов, смешаны
+// кириллица и латиница.
+func splitPersonName(value string) (words []string, initials int, ok bool) {
+	fields := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	if len(fields) == 0 || len(fields) > 4 {
+		return nil, 0, false
+	}
+
+	latin, cyrillic := false, false
+	for _, field := range fields {
+		if n := countInitials(field); n > 0 {
+			initials += n
+			continue
+		}
+		for _, r := range field {
+			switch {
+			case r == '-':
+			case isLatin(r):
+				latin = true
+			case unicode.Is(unicode.Cyrillic, r):
+				cyrillic = true
+			default:
+				return nil, 0, false
+			}
+		}
+		if strings.Trim(field, "-") != field {
+			return nil, 0, false
+		}
+		words = append(words, normalizeWord(field))
+	}
+	if latin && cyrillic || len(words) == 0 || len(words) > 3 || initials > 2 {
+		return nil, 0, false
+	}
+
+	if latin {
+		for i, w := range words {
+			words[i] = transliterate(w)
+		}
+	}
+	return words, initials, true
+}
+
+// countInitials возвращает число инициалов в слове вида И., И.И. или
+// И.-И.; для других слов — 0.
+func countInitials(field string) int {
+	runes := []rune(field)
+	n := 0
+	for i := 0; i < len(runes); {
+		if !unicode.IsUpper(runes[i]) || i+1 >= len(runes) || runes[i+1] != '.' {
+			return 0
+		}
+		n++
+		i += 2
+		if i < len(runes) && runes[i] == '-' {
+			i++
+		}
+	}
+	return n
+}
+
+// isPatronymic узнает отчество по окончанию: -вич, -вна, -ична (Ильинична,
+// Кузьминична) и мужские отчества на -ич (Ильич, Кузьмич).
+func isPatronymic(word string) bool {
+	for _, ending := range []string{"вич", "вна", "ична", "ич"} {
+		if strings.HasSuffix(word, ending) && len([]rune(word)) > len([]rune(ending))+2 {
+			return true
+		}
+	}
+	return false
+}
+
+// patronymicOfName сообщает, что отчество образовано от имени из словаря.
+func patronymicOfName(word string) bool {
+	for _, p := range patronymicNames {
+		stem, ok := strings.CutSuffix(word, p.ending)
+		if !ok || stem == "" {
+			continue
+		}
+		if firstNames[patronymicStems[stem]] {
+			return true
+		}
+		for _, ending := range p.names {
+			if firstNames[stem+ending] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSurname ищет фамилию в словаре, в том числе двойную (Петров-Водкин) и
+// в женской форме.
+func isSurname(word string) bool {
+	for _, part := range strings.Split(word, "-") {
+		if surnames[part] {
+			return true
+		}
+		for _, e := range feminineSurnameEndings {
+			if strings.HasSuffix(part, e.feminine) && surnames[strings.TrimSuffix(part, e.feminine)+e.masculine] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasSurnameEnding узнает фамилию не из словаря по окончанию из
+// surnameEndings, если до окончания в слове не меньше двух букв.
+func hasSurnameEnding(word string) bool {
+	for _, ending := range surnameEndings {
+		if strings.HasSuffix(word, ending) && len([]rune(word)) > len([]rune(ending))+1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestDetectPersonName(t *testing.T) {
+	tests := []struct {
+		value string
+		kind  string
+	}{
+		{"Иванов Иван Иванович", "полное ФИО"},
+		{"Анна Сергеевна Петрова", "полное ФИО"},
+		{"Иван Петрович Ремонт", ""},
+		{"Анна Сергеевна Бухгалтер", ""},
+		{"Петрова Анна", "фамилия и имя"},
+		{"Иванов И.И.", "фамилия и инициалы"},
+		{"Анна Сергеевна", "имя и отчество"},
+		{"Ivanov Ivan", "фамилия и имя"},
+		{"Иван", "имя"},
+		{"Иванович", "отчество"},
+		{"Юрьевна", "отчество"},
+		{"Ильич", "отчество"},
+		{"Кузьминична", "отчество"},
+		{"Михайлович", "отчество"},
+		{"Кирпич", ""},
+		{"Газпромович", ""},
+		{"москвич", ""},
+		{"кулич", ""},
+		{"ООО Ромашка", ""},
+		{"Москва 2024", ""},
+	}
+	for _, tt := range tests {
+		if _, kind := detectPersonName(tt.value); kind != tt.kind {
+			t.Errorf("%q: %q, ожидалось %q", tt.value, kind, tt.kind)
+		}
+	}
+}
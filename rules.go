@@ -0,0 +1,383 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRules — встроенный набор правил, см. rules.yaml.
+//
+//go:embed rules.yaml
+var defaultRules []byte
+
+// Rule — правило обнаружения одного типа ПДн по имени колонки и значениям.
+type Rule struct {
+	ID        string   `yaml:"id"`
+	Name      string   `yaml:"name"`
+	Category  string   `yaml:"category"`
+	Headers   []string `yaml:"headers"`
+	Values    []string `yaml:"values"`
+	Negative  []string `yaml:"negative"`
+	Validator string   `yaml:"validator"`
+	// Detector — распознаватель значений целиком (см. detectors), если
+	// регулярных выражений values недостаточно
+	Detector string `yaml:"detector"`
+	// Types — классы типов колонок (typeClasses), в которых обычно хранится
+	// этот тип ПДн; в колонке другого типа уверенность снижается
+	Types    []string `yaml:"types"`
+	Disabled bool     `yaml:"disabled"`
+	// MinDistinct — наименьшая доля различных значений среди совпавших:
+	// если значения почти одинаковы (заглушки, тестовые данные),
+	// уверенность снижается
+	MinDistinct float64 `yaml:"min_distinct"`
+	// ValueWeight — вес значения, совпавшего с values (по умолчанию 1):
+	// меньше 1 для форматов, которые встречаются и у других номеров
+	ValueWeight float64 `yaml:"value_weight"`
+	// Context — id правил, ПДн которых в другой колонке той же таблицы
+	// повышают уверенность в этом типе (см. applyTableContext)
+	Context []string `yaml:"context"`
+	// Thresholds переопределяет общие пороги для этого правила
+	Thresholds *Thresholds `yaml:"thresholds"`
+
+	// keywords — Headers, записанные слитно по словам (см. splitName)
+	keywords []string
+	patterns []*regexp.Regexp
+	validate *validator
+	detect   detector
+}
+
+// RuleSet — файл правил. Формат YAML; JSON также принимается.
+type RuleSet struct {
+	// Replace в пользовательском файле отключает встроенные правила
+	Replace    bool       `yaml:"replace"`
+	Thresholds Thresholds `yaml:"thresholds"`
+	// Abbreviations — сокращения в именах колонок и их полная запись:
+	// dr → дата рождения. Пустая запись в пользовательском файле убирает
+	// встроенное сокращение
+	Abbreviations map[string]string `yaml:"abbreviations"`
+	Rules         []*Rule           `yaml:"rules"`
+}
+
+// Thresholds — пороги уверенности в типе ПДн колонки (см. evidence.score):
+// от Confirmed ПДн считаются подтвержденными, от Possible — возможными, ниже
+// признаки считаются случайными. Для типа, найденного только в значениях,
+// уверенность равна доле совпавших значений.
+type Thresholds struct {
+	Confirmed float64 `yaml:"confirmed"`
+	Possible  float64 `yaml:"possible"`
+}
+
+// Вердикты по уверенности в типе ПДн.
+const (
+	verdictConfirmed = "confirmed"
+	verdictPossible  = "possible"
+	verdictNone      = "none"
+)
+
+// override возвращает пороги t, замененные ненулевыми порогами o.
+func (t Thresholds) override(o Thresholds) Thresholds {
+	if o.Confirmed != 0 {
+		t.Confirmed = o.Confirmed
+	}
+	if o.Possible != 0 {
+		t.Possible = o.Possible
+	}
+	return t
+}
+
+func (t Thresholds) check() error {
+	if t.Possible <= 0 || t.Confirmed > 1 || t.Possible > t.Confirmed {
+		return fmt.Errorf("пороги уверенности должны быть 0 < possible (%g) <= confirmed (%g) <= 1", t.Possible, t.Confirmed)
+	}
+	return nil
+}
+
+// verdict сопоставляет уверенность с порогами.
+func (t Thresholds) verdict(confidence float64) string {
+	switch {
+	case confidence >= t.Confirmed:
+		return verdictConfirmed
+	case confidence >= t.Possible:
+		return verdictPossible
+	}
+	return verdictNone
+}
+
+// loadRules возвращает встроенные правила, дополненные правилами из файла
+// fileName, если он указан. Ненулевые thresholds (из флагов) заменяют
+// общие пороги из файлов правил.
+func loadRules(fileName string, thresholds Thresholds) (*RuleSet, error) {
+	rules, err := parseRules(defaultRules, "встроенные правила")
+	if err != nil {
+		return nil, err
+	}
+
+	if fileName != "" {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("чтение правил: %v", err)
+		}
+		custom, err := parseRules(data, fileName)
+		if err != nil {
+			return nil, err
+		}
+		if custom.Replace {
+			rules.Rules = nil
+		}
+		rules.merge(custom)
+
+		if len(rules.Rules) == 0 {
+			return nil, fmt.Errorf("%s: не осталось ни одного правила", fileName)
+		}
+	}
+
+	rules.Thresholds = rules.Thresholds.override(thresholds)
+	if err := rules.Thresholds.check(); err != nil {
+		return nil, err
+	}
+	for _, r := range rules.Rules {
+		if err := rules.thresholds(r).check(); err != nil {
+			return nil, fmt.Errorf("правило %s: %v", r.ID, err)
+		}
+	}
+	return rules, nil
+}
+
+func parseRules(data []byte, source string) (*RuleSet, error) {
+	var rs RuleSet
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("разбор правил %s: %v", source, err)
+	}
+
+	abbreviations := make(map[string]string, len(rs.Abbreviations))
+	for short, full := range rs.Abbreviations {
+		abbreviations[strings.ToLower(short)] = strings.ToLower(full)
+	}
+	rs.Abbreviations = abbreviations
+
+	seen := make(map[string]bool)
+	for i, r := range rs.Rules {
+		if r.ID == "" {
+			return nil, fmt.Errorf("%s: у правила %d не указан id", source, i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("%s: правило %s указано дважды", source, r.ID)
+		}
+		seen[r.ID] = true
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("%s: правило %s: %v", source, r.ID, err)
+		}
+	}
+	return &rs, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Disabled {
+		return nil
+	}
+	if r.Name == "" {
+		return errors.New("не указано name")
+	}
+	if len(r.Headers) == 0 && len(r.Values) == 0 && r.Detector == "" {
+		return errors.New("нужны headers, values или detector")
+	}
+
+	for i, kw := range r.Headers {
+		r.Headers[i] = strings.ToLower(kw)
+		r.keywords = append(r.keywords, strings.Join(splitName(kw), ""))
+	}
+	for i, kw := range r.Negative {
+		r.Negative[i] = strings.ToLower(kw)
+	}
+	for _, expr := range r.Values {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("values: %v", err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	if r.ValueWeight < 0 || r.ValueWeight > 1 {
+		return fmt.Errorf("value_weight должен быть от 0 до 1, указано %g", r.ValueWeight)
+	}
+	if r.ValueWeight == 0 {
+		r.ValueWeight = 1
+	}
+
+	for _, class := range r.Types {
+		if !contains(typeClasses, class) {
+			return fmt.Errorf("неизвестный класс типа %q, доступны: %s", class, strings.Join(typeClasses, ", "))
+		}
+	}
+
+	if r.Validator != "" {
+		v, ok := validators[r.Validator]
+		if !ok {
+			return fmt.Errorf("неизвестный validator %q, доступны: %s", r.Validator, strings.Join(validatorNames(), ", "))
+		}
+		r.validate = &v
+	}
+
+	if r.Detector != "" {
+		d, ok := detectors[r.Detector]
+		if !ok {
+			return fmt.Errorf("неизвестный detector %q, доступны: %s", r.Detector, strings.Join(detectorNames(), ", "))
+		}
+		r.detect = d
+	}
+	return nil
+}
+
+// merge заменяет правила с совпадающим id, добавляет новые и убирает
+// отключенные. Заданные в custom пороги заменяют общие, сокращения
+// дополняют встроенные.
+func (rs *RuleSet) merge(custom *RuleSet) {
+	rs.Thresholds = rs.Thresholds.override(custom.Thresholds)
+	for short, full := range custom.Abbreviations {
+		if full == "" {
+			delete(rs.Abbreviations, short)
+		} else {
+			rs.Abbreviations[short] = full
+		}
+	}
+
+	index := make(map[string]int)
+	for i, r := range rs.Rules {
+		index[r.ID] = i
+	}
+	for _, r := range custom.Rules {
+		if i, ok := index[r.ID]; ok {
+			rs.Rules[i] = r
+		} else {
+			index[r.ID] = len(rs.Rules)
+			rs.Rules = append(rs.Rules, r)
+		}
+	}
+
+	enabled := rs.Rules[:0]
+	for _, r := range rs.Rules {
+		if !r.Disabled {
+			enabled = append(enabled, r)
+		}
+	}
+	rs.Rules = enabled
+}
+
+// thresholds возвращает пороги уверенности для правила r.
+func (rs *RuleSet) thresholds(r *Rule) Thresholds {
+	if r.Thresholds == nil {
+		return rs.Thresholds
+	}
+	return rs.Thresholds.override(*r.Thresholds)
+}
+
+// matchHeader возвращает правила, ключевые слова которых есть среди слов
+// имени колонки, с самым сильным из совпавших слов (см.
+// columnName.keywordStrength).
+func (rs *RuleSet) matchHeader(name string) []headerMatch {
+	cn := parseColumnName(name, rs.Abbreviations)
+	plain := strings.Join(splitName(name), " ")
+
+	var matched []headerMatch
+	for _, r := range rs.Rules {
+		if cn.containsAny(r.Negative) {
+			continue
+		}
+		best := headerMatch{rule: r}
+		for i, kw := range r.keywords {
+			strength, variant := cn.keywordStrength(kw)
+			if strength <= best.strength {
+				continue
+			}
+			best.keyword, best.strength, best.reading = r.Headers[i], strength, ""
+			if reading := strings.Join(cn.variants[variant], " "); reading != plain {
+				best.reading = reading
+			}
+		}
+		if best.strength > 0 {
+			matched = append(matched, best)
+		}
+	}
+	return matched
+}
+
+// excludedByName возвращает правила, слова negative которых есть в имени
+// колонки: в такой колонке они не срабатывают и по значениям (10 цифр в
+// колонке «Водит_удост» — не паспорт).
+func (rs *RuleSet) excludedByName(name string) map[*Rule]bool {
+	cn := parseColumnName(name, rs.Abbreviations)
+	excluded := make(map[*Rule]bool)
+	for _, r := range rs.Rules {
+		if cn.containsAny(r.Negative) {
+			excluded[r] = true
+		}
+	}
+	return excluded
+}
+
+// valueMatch — правило, регулярное выражение или detector которого
+// сработали на значении. Если ни один фрагмент не прошел validator,
+// rejected содержит причину, иначе validated — пояснение проверки (для
+// правил с validator или detector), weight — вес значения (см. detector).
+type valueMatch struct {
+	rule      *Rule
+	weight    float64
+	validated string
+	rejected  string
+}
+
+// matchValue возвращает правила, сработавшие на значении.
+func (rs *RuleSet) matchValue(value string) []valueMatch {
+	lower := strings.ToLower(value)
+	var matched []valueMatch
+	for _, r := range rs.Rules {
+		if containsAny(lower, r.Negative) {
+			continue
+		}
+		if m, ok := r.matchValue(value, lower); ok {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+// matchValue проверяет значение выражениями правила, а если они не нашли
+// подходящего фрагмента — detector. Выражения получают значение в нижнем
+// регистре lower, detector — как есть.
+func (r *Rule) matchValue(value, lower string) (valueMatch, bool) {
+	m, found := r.matchPatterns(lower)
+	if (!found || m.rejected != "") && r.detect != nil {
+		if weight, kind := r.detect(value); weight > 0 {
+			return valueMatch{rule: r, weight: weight, validated: kind}, true
+		}
+	}
+	return m, found
+}
+
+func (r *Rule) matchPatterns(value string) (valueMatch, bool) {
+	m := valueMatch{rule: r, weight: r.ValueWeight}
+	found := false
+	for _, re := range r.patterns {
+		for _, fragment := range re.FindAllString(value, -1) {
+			found = true
+			if r.validate == nil {
+				return m, true
+			}
+			err := r.validate.check(fragment)
+			if err == nil {
+				m.validated, m.rejected = r.validate.passed, ""
+				return m, true
+			}
+			if m.rejected == "" {
+				m.rejected = err.Error()
+			}
+		}
+	}
+	return m, found
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCyrillicWordBoundaries проверяет, что выражения правил с русскими
+// словами находят их целиком: \b в RE2 учитывает только ASCII-буквы.
+func TestCyrillicWordBoundaries(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]*Rule)
+	for _, r := range rules.Rules {
+		byID[r.ID] = r
+	}
+
+	tests := []struct {
+		rule  string
+		value string
+		want  bool
+	}{
+		{"family", "дочь", true},
+		{"family", "муж, двое детей", true},
+		{"family", "сын: Иван", true},
+		{"family", "мужской", false},
+		{"family", "сыновья", false},
+		{"military", "ВУС 123456", true},
+		{"military", "военно-учетная специальность", true},
+		{"gender", "м", true},
+		{"gender", "Ж", true},
+		{"gender", "женский", true},
+		{"gender", "male", true},
+		{"gender", "мама", false},
+		{"gender", "жир", false},
+	}
+	for _, tt := range tests {
+		r := byID[tt.rule]
+		if r == nil {
+			t.Fatalf("нет правила %s", tt.rule)
+		}
+		_, got := r.matchValue(tt.value, strings.ToLower(tt.value))
+		if got != tt.want {
+			t.Errorf("%s(%q) = %v, ожидалось %v", tt.rule, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Уверенность в типе ПДн колонки складывается из независимых признаков:
+// имени колонки (с весом headerWeight, умноженным на силу совпадения
+// ключевого слова) и доли совпавших значений. Затем она снижается, если
+// значения не прошли validator или тип колонки не подходит для типа ПДн.
+const (
+	headerWeight = 0.75
+	// rejectedPenalty — множитель уверенности, если все найденные значения
+	// отклонены проверкой
+	rejectedPenalty = 0.5
+	// typeMismatchFactor применяется, если тип колонки не из types правила
+	typeMismatchFactor = 0.5
+	// uniformFactor применяется, если различных значений меньше
+	// min_distinct правила; проверяется от uniformMinValues совпадений
+	uniformFactor    = 0.5
+	uniformMinValues = 5
+	// contextFactor — множитель уверенности, если в таблице есть ПДн из
+	// context правила
+	contextFactor = 1.5
+)
+
+// Сила совпадения ключевого слова с именем колонки.
+const (
+	keywordWord   = 1.0  // слово целиком: phone, last_name, lastName
+	keywordStem   = 0.85 // начало слова: фамил в фамилия
+	keywordInside = 0.5  // внутри слова: phone в cellphone
+)
+
+// typeClasses — классы типов колонок для поля types правил.
+var typeClasses = []string{"text", "number", "date", "bool", "binary"}
+
+// headerMatch — правило, ключевое слово которого найдено в имени колонки.
+type headerMatch struct {
+	rule     *Rule
+	keyword  string
+	strength float64
+	// reading — имя после раскрытия сокращений и транслитерации, если оно
+	// отличается от исходного
+	reading string
+}
+
+// typeNameClasses — классы типов СУБД, BSON, Parquet и Avro по имени типа
+// без параметров (varchar(100) → varchar).
+var typeNameClasses = map[string]string{
+	"string": "text", "character": "text", "json": "text", "jsonb": "text", "xml": "text",
+	"uuid": "text", "uniqueidentifier": "text", "citext": "text", "enum": "text", "set": "text",
+
+	"bytea": "binary", "image": "binary", "raw": "binary", "bindata": "binary", "bytes": "binary",
+	"fixed": "binary", "byte_array": "binary", "fixed_len_byte_array": "binary",
+
+	"bool": "bool", "boolean": "bool", "bit": "bool",
+
+	"year": "date",
+
+	"int": "number", "uint": "number", "tinyint": "number", "smallint": "number",
+	"mediumint": "number", "bigint": "number", "hugeint": "number",
+	"integer": "number", "serial": "number", "smallserial": "number", "bigserial": "number",
+	"numeric": "number", "decimal": "number", "dec": "number", "number": "number",
+	"float": "number", "double": "number", "real": "number", "money": "number",
+	"smallmoney": "number", "long": "number", "binary_double": "number", "binary_float": "number",
+}
+
+// columnTypeClass относит тип колонки СУБД, BSON или файла к одному из
+// typeClasses по имени типа: первому слову без параметров (point и
+// interval не относятся к числам, хотя содержат int). Для неизвестных и
+// смешанных типов возвращает "".
+func columnTypeClass(dataType string) string {
+	words := strings.FieldsFunc(strings.ToLower(dataType), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	name := words[0]
+	if name == "local" && len(words) > 1 {
+		// Avro: local-timestamp-millis
+		name = words[1]
+	}
+	if name == "long" && len(words) > 1 && words[1] == "raw" {
+		return "binary"
+	}
+	// Размер в имени типа: int4, uint32, float8, nvarchar2
+	name = strings.TrimRight(name, "0123456789")
+	if class, ok := typeNameClasses[name]; ok {
+		return class
+	}
+
+	switch {
+	case hasAnySuffix(name, "char", "text", "clob"):
+		return "text"
+	case hasAnySuffix(name, "blob", "binary"):
+		return "binary"
+	case strings.HasPrefix(name, "date") || strings.HasPrefix(name, "time") || name == "smalldatetime":
+		return "date"
+	}
+	return ""
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// evidence — признаки одного типа ПДн в колонке.
+type evidence struct {
+	header            *headerMatch
+	matched, rejected int
+	// weight — сумма весов совпавших значений (см. valueMatch.weight)
+	weight     float64
+	sampleSize int
+	// reason — причина, по которой validator отклонил первое значение
+	reason string
+	// kinds — сколько значений получило каждое пояснение проверки
+	kinds     map[string]int
+	kindOrder []string
+	// distinct — различные совпавшие значения
+	distinct map[string]bool
+}
+
+// addMatch учитывает совпавшее значение value.
+func (e *evidence) addMatch(value string, m valueMatch) {
+	e.matched++
+	e.weight += m.weight
+	if e.distinct == nil {
+		e.distinct = make(map[string]bool)
+	}
+	e.distinct[value] = true
+	if m.validated == "" {
+		return
+	}
+	if e.kinds == nil {
+		e.kinds = make(map[string]int)
+	}
+	if e.kinds[m.validated] == 0 {
+		e.kindOrder = append(e.kindOrder, m.validated)
+	}
+	e.kinds[m.validated]++
+}
+
+// ratio возвращает долю совпавших значений выборки с учетом их весов.
+func (e evidence) ratio() float64 {
+	if e.sampleSize == 0 {
+		return 0
+	}
+	return e.weight / float64(e.sampleSize)
+}
+
+// validation описывает результат проверки совпавших значений: пояснение,
+// если оно одно, иначе пояснения с числом значений.
+func (e evidence) validation() string {
+	if len(e.kindOrder) == 1 {
+		return e.kindOrder[0]
+	}
+	parts := make([]string, len(e.kindOrder))
+	for i, kind := range e.kindOrder {
+		parts[i] = fmt.Sprintf("%s: %d", kind, e.kinds[kind])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// score возвращает уверенность от 0 до 1 и ее обоснование.
+func (e evidence) score(rule *Rule, column ColumnInfo) (float64, string) {
+	var parts []string
+	miss := 1.0
+
+	if e.header != nil {
+		p := headerWeight * e.header.strength
+		miss *= 1 - p
+		name := "имя колонки"
+		if e.header.reading != "" {
+			name = fmt.Sprintf("имя колонки (%s)", e.header.reading)
+		}
+		parts = append(parts, fmt.Sprintf("%s: «%s» — %s (%.2f)", name, e.header.keyword, describeStrength(e.header.strength), p))
+	}
+
+	if e.matched > 0 || e.rejected > 0 {
+		ratio := e.ratio()
+		miss *= 1 - ratio
+		parts = append(parts, fmt.Sprintf("значения: %d из %d (%.2f)", e.matched, e.sampleSize, ratio))
+	}
+
+	confidence := 1 - miss
+
+	if e.rejected > 0 {
+		factor := 1 - (1-rejectedPenalty)*float64(e.rejected)/float64(e.matched+e.rejected)
+		confidence *= factor
+		parts = append(parts, fmt.Sprintf("не прошли проверку %d: %s (×%.2f)", e.rejected, e.reason, factor))
+	}
+
+	if rule.MinDistinct > 0 && e.matched >= uniformMinValues && float64(len(e.distinct)) < rule.MinDistinct*float64(e.matched) {
+		confidence *= uniformFactor
+		parts = append(parts, fmt.Sprintf("различных значений %d из %d (×%.2f)", len(e.distinct), e.matched, uniformFactor))
+	}
+
+	if class := columnTypeClass(column.DataType); class != "" && len(rule.Types) > 0 && !contains(rule.Types, class) {
+		confidence *= typeMismatchFactor
+		parts = append(parts, fmt.Sprintf("тип %s нетипичен для типа ПДн (×%.2f)", column.DataType, typeMismatchFactor))
+	}
+
+	return math.Round(confidence*100) / 100, strings.Join(parts, "; ")
+}
+
+func describeStrength(strength float64) string {
+	switch strength {
+	case keywordWord:
+		return "слово целиком"
+	case keywordStem:
+		return "начало слова"
+	}
+	return "внутри слова"
+}
+
+// applyTableContext повышает уверенность в типах ПДн, у правил которых
+// задан context, если в другой колонке той же таблицы найдены ПДн одного
+// из типов context: даты с возрастом взрослого рядом с ФИО — скорее всего
+// даты рождения. Уверенность умножается на contextFactor, вердикт
+// пересчитывается.
+func applyTableContext(rules *RuleSet, results []PDNResult) {
+	for i := range results {
+		res := &results[i]
+		if res.rule == nil || len(res.rule.Context) == 0 || res.Confidence == 0 {
+			continue
+		}
+
+		for _, other := range results {
+			if other.rule == nil || other.ColumnName == res.ColumnName || !isPDNResult(other) || !contains(res.rule.Context, other.rule.ID) {
+				continue
+			}
+			res.Confidence = math.Round(min(1, res.Confidence*contextFactor)*100) / 100
+			res.Explanation += fmt.Sprintf("; в таблице есть %s (колонка %s) (×%.2f)", other.PDNType, other.ColumnName, contextFactor)
+			res.Verdict = rules.thresholds(res.rule).verdict(res.Confidence)
+			break
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestColumnTypeClass(t *testing.T) {
+	tests := []struct {
+		dataType string
+		class    string
+	}{
+		{"varchar(100)", "text"},
+		{"nvarchar2", "text"},
+		{"character varying", "text"},
+		{"tinytext", "text"},
+		{"uniqueidentifier", "text"},
+		{"int", "number"},
+		{"mediumint", "number"},
+		{"int4", "number"},
+		{"int(64,true)", "number"},
+		{"double precision", "number"},
+		{"binary_double", "number"},
+		{"date", "date"},
+		{"datetime2", "date"},
+		{"timestamp with time zone", "date"},
+		{"timestamp(isadjustedtoutc=true,unit=millis)", "date"},
+		{"local-timestamp-millis", "date"},
+		{"bytea", "binary"},
+		{"long raw", "binary"},
+		{"varbinary(16)", "binary"},
+		{"boolean", "bool"},
+		{"point", ""},
+		{"interval", ""},
+		{"interval day to second", ""},
+		{"objectId", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if class := columnTypeClass(tt.dataType); class != tt.class {
+			t.Errorf("%q: %q, ожидалось %q", tt.dataType, class, tt.class)
+		}
+	}
+}
+
+// testEvidence возвращает признаки matched совпавших значений из
+// sampleSize, из которых distinct различных.
+func testEvidence(matched, distinct, sampleSize int) evidence {
+	e := evidence{sampleSize: sampleSize}
+	for i := 0; i < matched; i++ {
+		e.addMatch(fmt.Sprint(i%distinct), valueMatch{weight: 1})
+	}
+	return e
+}
+
+func TestEvidenceScore(t *testing.T) {
+	rule := &Rule{ID: "birth_date", Types: []string{"date", "text"}, MinDistinct: 0.5}
+	header := &headerMatch{rule: rule, keyword: "birth", strength: keywordWord}
+	stem := &headerMatch{rule: rule, keyword: "рожд", strength: keywordStem}
+
+	withHeader := func(e evidence, h *headerMatch) evidence {
+		e.header = h
+		return e
+	}
+
+	tests := []struct {
+		name       string
+		e          evidence
+		dataType   string
+		confidence float64
+	}{
+		{"только имя колонки", evidence{header: header, sampleSize: 10}, "date", 0.75},
+		{"только начало слова", evidence{header: stem, sampleSize: 10}, "date", 0.64},
+		{"только значения", testEvidence(4, 4, 10), "date", 0.4},
+		{"имя и значения", withHeader(testEvidence(4, 4, 10), header), "date", 0.85},
+		{"нетипичный тип", testEvidence(4, 4, 10), "integer", 0.2},
+		{"неизвестный тип", testEvidence(4, 4, 10), "point", 0.4},
+		{"одинаковые значения", testEvidence(10, 2, 10), "date", 0.5},
+		{"одинаковые, но мало совпадений", testEvidence(4, 1, 10), "date", 0.4},
+		{"различных ровно min_distinct", testEvidence(10, 5, 10), "date", 1},
+		{"одинаковые в нетипичном типе", testEvidence(10, 2, 10), "bigint", 0.25},
+	}
+	for _, tt := range tests {
+		confidence, explanation := tt.e.score(rule, ColumnInfo{ColumnName: "c", DataType: tt.dataType})
+		if confidence != tt.confidence {
+			t.Errorf("%s: %v (%s), ожидалось %v", tt.name, confidence, explanation, tt.confidence)
+		}
+	}
+}
+
+func TestEvidenceScoreRejected(t *testing.T) {
+	rule := &Rule{ID: "snils"}
+	e := testEvidence(5, 5, 10)
+	e.rejected = 5
+	e.reason = "неверное контрольное число"
+
+	// Половина найденных значений отклонена: 0.5 × (1 − 0.5×0.5)
+	if confidence, explanation := e.score(rule, ColumnInfo{}); confidence != 0.38 {
+		t.Errorf("%v (%s), ожидалось 0.38", confidence, explanation)
+	}
+}
+
+func TestApplyTableContext(t *testing.T) {
+	rules, err := loadRules("", Thresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]*Rule)
+	for _, r := range rules.Rules {
+		byID[r.ID] = r
+	}
+	result := func(column, id string, confidence float64) PDNResult {
+		r := byID[id]
+		return PDNResult{
+			ColumnName: column,
+			PDNType:    r.Name,
+			Confidence: confidence,
+			Verdict:    rules.thresholds(r).verdict(confidence),
+			rule:       r,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		results    []PDNResult
+		confidence float64
+		verdict    string
+	}{
+		{
+			"ФИО в другой колонке",
+			[]PDNResult{result("d", "birth_date", 0.5), result("name", "fio", 0.9)},
+			0.75, verdictConfirmed,
+		},
+		{
+			"не больше 1",
+			[]PDNResult{result("d", "birth_date", 0.8), result("name", "fio", 0.9)},
+			1, verdictConfirmed,
+		},
+		{
+			"ФИО не найдено",
+			[]PDNResult{result("d", "birth_date", 0.5), result("name", "fio", 0.1)},
+			0.5, verdictPossible,
+		},
+		{
+			"ФИО в той же колонке",
+			[]PDNResult{result("d", "birth_date", 0.5), result("d", "fio", 0.9)},
+			0.5, verdictPossible,
+		},
+		{
+			"тип не из context",
+			[]PDNResult{result("d", "birth_date", 0.5), result("bank", "swift", 0.9)},
+			0.5, verdictPossible,
+		},
+		{
+			"нет признаков",
+			[]PDNResult{result("d", "birth_date", 0), result("name", "fio", 0.9)},
+			0, verdictNone,
+		},
+	}
+	for _, tt := range tests {
+		applyTableContext(rules, tt.results)
+		res := tt.results[0]
+		if res.Confidence != tt.confidence || res.Verdict != tt.verdict {
+			t.Errorf("%s: %v %s (%s), ожидалось %v %s", tt.name, res.Confidence, res.Verdict, res.Explanation, tt.confidence, tt.verdict)
+		}
+	}
+
+	// Правило без context не меняется
+	results := []PDNResult{result("e", "email", 0.5), result("name", "fio", 0.9)}
+	applyTableContext(rules, results)
+	if results[0].Confidence != 0.5 {
+		t.Errorf("email: %v, ожидалось 0.5", results[0].Confidence)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// resolveCredentials заполняет логин и пароль из файлов, stdin или переменных
+// окружения. Интерактивный ввод выполняется позже, в promptMissing.
+func (o *Options) resolveCredentials() error {
+	if o.CredentialsFile != "" {
+		user, password, err := readCredentialsFile(o.CredentialsFile)
+		if err != nil {
+			return err
+		}
+		if o.User == "" {
+			o.User = user
+		}
+		o.Password = password
+	}
+
+	if o.PasswordFile != "" {
+		password, err := readSecretFile(o.PasswordFile)
+		if err != nil {
+			return err
+		}
+		o.Password = password
+	}
+
+	if o.PasswordStdin {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			return errors.New("--password-stdin ожидает пароль из канала, а не с терминала")
+		}
+		password, err := readFirstLine(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("чтение пароля из stdin: %v", err)
+		}
+		o.Password = password
+		// stdin уже прочитан, дальнейшие вопросы задать не получится
+		o.NonInteractive = true
+	}
+
+	if o.Password == "" && o.PasswordEnv != "" {
+		o.Password = os.Getenv(o.PasswordEnv)
+	}
+	if o.Password == "" {
+		o.Password = os.Getenv("PDN_PASSWORD")
+	}
+
+	return nil
+}
+
+// readCredentialsFile читает файл вида
+//
+//	user=auditor
+//	password=secret
+//
+// Файл должен быть доступен только владельцу.
+func readCredentialsFile(path string) (string, string, error) {
+	if err := checkSecretFilePerm(path); err != nil {
+		return "", "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("файл учетных данных: %v", err)
+	}
+	defer file.Close()
+
+	var user, password string
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// Строку не выводим: без = это может быть сам пароль
+			return "", "", fmt.Errorf("файл учетных данных %s: строка %d не в формате ключ=значение", path, n)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "user", "username", "login":
+			user = strings.TrimSpace(value)
+		case "password":
+			password = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("файл учетных данных: %v", err)
+	}
+	if password == "" {
+		return "", "", fmt.Errorf("файл учетных данных %s не содержит password", path)
+	}
+
+	return user, password, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	if err := checkSecretFilePerm(path); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("файл пароля: %v", err)
+	}
+	defer file.Close()
+
+	secret, err := readFirstLine(file)
+	if err != nil {
+		return "", fmt.Errorf("файл пароля: %v", err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("файл пароля %s пуст", path)
+	}
+	return secret, nil
+}
+
+func checkSecretFilePerm(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("файл с секретом: %v", err)
+	}
+	// На Windows права в стиле unix не отражают ACL, проверка не имеет смысла
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("файл %s доступен группе или другим пользователям (%v), выполните chmod 600",
+			path, info.Mode().Perm())
+	}
+	return nil
+}
+
+func readFirstLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPassword запрашивает пароль без отображения вводимых символов.
+func readPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return readFirstLine(os.Stdin)
+	}
+
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
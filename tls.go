@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Режимы шифрования соединения (--encrypt)
+const (
+	encryptDisable = "disable" // шифрование не используется совсем
+	encryptFalse   = "false"   // шифруется только вход (поведение драйвера по умолчанию)
+	encryptTrue    = "true"    // TLS обязателен
+	encryptStrict  = "strict"  // TDS 8.0: TLS до начала обмена TDS, сертификат проверяется всегда
+)
+
+var encryptModes = []string{encryptDisable, encryptFalse, encryptTrue, encryptStrict}
+
+// TLSOptions — параметры шифрования соединения с БД.
+type TLSOptions struct {
+	Encrypt           string
+	TrustServerCert   bool
+	CAFile            string
+	HostInCert        string
+	MinVersion        string
+	RequireEncryption bool
+}
+
+func (t *TLSOptions) validate() error {
+	if t.Encrypt != "" && !contains(encryptModes, t.Encrypt) {
+		return fmt.Errorf("неизвестный режим шифрования %q, доступны: %s", t.Encrypt, strings.Join(encryptModes, ", "))
+	}
+	if t.RequireEncryption {
+		if t.Encrypt == encryptDisable || t.Encrypt == encryptFalse {
+			return fmt.Errorf("--require-encryption несовместим с --encrypt %s", t.Encrypt)
+		}
+		if t.TrustServerCert {
+			return errors.New("--require-encryption несовместим с --trust-server-cert: сертификат сервера должен проверяться")
+		}
+	}
+	if t.TrustServerCert && t.CAFile != "" {
+		return errors.New("--trust-server-cert отключает проверку сертификата, --ca-file в этом случае не используется")
+	}
+	return nil
+}
+
+// effectiveEncrypt возвращает режим шифрования, передаваемый драйверу.
+func (t *TLSOptions) effectiveEncrypt() string {
+	if t.RequireEncryption && t.Encrypt == "" {
+		return encryptTrue
+	}
+	return t.Encrypt
+}
+
+func (t *TLSOptions) applyTo(query url.Values) {
+	if encrypt := t.effectiveEncrypt(); encrypt != "" {
+		query.Set("encrypt", encrypt)
+	}
+	if t.TrustServerCert {
+		query.Set("trustservercertificate", "true")
+	}
+	if t.CAFile != "" {
+		query.Set("certificate", t.CAFile)
+	}
+	if t.HostInCert != "" {
+		query.Set("hostnameincertificate", t.HostInCert)
+	}
+	if t.MinVersion != "" {
+		query.Set("tlsmin", t.MinVersion)
+	}
+}
+
+func describeSecurity(t *TLSOptions, encryptOption, authScheme string) string {
+	var parts []string
+	if strings.EqualFold(encryptOption, "TRUE") {
+		parts = append(parts, "TLS")
+		if t.effectiveEncrypt() == encryptStrict {
+			parts = append(parts, "TDS 8.0 strict")
+		}
+		switch {
+		case t.effectiveEncrypt() == "" || t.TrustServerCert:
+			parts = append(parts, "сертификат не проверяется")
+		case t.CAFile != "":
+			parts = append(parts, "сертификат проверен по "+t.CAFile)
+		default:
+			parts = append(parts, "сертификат проверен")
+		}
+	} else {
+		parts = append(parts, "без шифрования данных")
+	}
+	if authScheme != "" {
+		parts = append(parts, "вход "+authScheme)
+	}
+	return strings.Join(parts, ", ")
+}
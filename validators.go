@@ -0,0 +1,391 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// validator проверяет фрагмент значения, найденный регулярным выражением
+// правила (поле validator). passed — пояснение для отчета, если проверка
+// пройдена; при непрохождении check возвращает причину.
+type validator struct {
+	check  func(string) error
+	passed string
+}
+
+var validators = map[string]validator{
+	"passport":   {validPassport, "серия паспорта допустима"},
+	"inn_person": {validINNPerson, "контрольные числа ИНН верны"},
+	"snils":      {validSNILS, "контрольное число СНИЛС верно"},
+	"ogrnip":     {validOGRNIP, "контрольное число ОГРНИП верно"},
+	"luhn":       {validLuhn, "контрольная сумма по алгоритму Луна верна"},
+
+	"oms":               {validOMS, "контрольная цифра полиса ОМС верна"},
+	"driver_license":    {validDriverLicense, "серия водительского удостоверения допустима"},
+	"vehicle_plate":     {validVehiclePlate, "номер и код региона допустимы"},
+	"foreign_passport":  {validForeignPassport, "серия загранпаспорта допустима"},
+	"military_id":       {validMilitaryID, "серия и номер военного билета допустимы"},
+	"birth_certificate": {validBirthCertificate, "серия свидетельства о рождении допустима"},
+
+	"card":    {validCard, "алгоритм Луна и БИН платежной системы верны"},
+	"account": {validAccount, "структура номера счета допустима"},
+	"iban":    {validIBAN, "контрольные цифры IBAN верны"},
+	"swift":   {validSWIFT, "код SWIFT/BIC допустим"},
+}
+
+func validatorNames() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// digitsOf оставляет в строке только цифры.
+func digitsOf(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// allSame сообщает, что строка состоит из одного повторяющегося символа:
+// 000000000000, 111111111111 и т.п. — заглушки, а не номера.
+func allSame(s string) bool {
+	return s != "" && strings.Count(s, s[:1]) == len(s)
+}
+
+// validPassport отсеивает номера с нулевой серией: кода региона 00 нет.
+func validPassport(s string) error {
+	digits := digitsOf(s)
+	if len(digits) != 10 {
+		return errors.New("в номере паспорта не 10 цифр")
+	}
+	if strings.HasPrefix(digits, "00") {
+		return errors.New("код региона в серии паспорта 00")
+	}
+	return nil
+}
+
+// validINNPerson проверяет контрольные числа ИНН физического лица
+// (12 цифр). ИНН организации (10 цифр) не относится к ПДн и не проверяется.
+func validINNPerson(s string) error {
+	d := digitsOf(s)
+	if len(d) != 12 {
+		return errors.New("в ИНН физлица не 12 цифр")
+	}
+	if allSame(d) {
+		return errors.New("ИНН из одинаковых цифр")
+	}
+	if innCheckDigit(d, []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) != d[10] ||
+		innCheckDigit(d, []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) != d[11] {
+		return errors.New("неверные контрольные числа ИНН")
+	}
+	return nil
+}
+
+func innCheckDigit(d string, weights []int) byte {
+	sum := 0
+	for i, w := range weights {
+		sum += int(d[i]-'0') * w
+	}
+	return byte('0' + sum%11%10)
+}
+
+// validSNILS проверяет контрольное число СНИЛС. Для номеров не больше
+// 001-001-998 контрольное число не рассчитывается.
+func validSNILS(s string) error {
+	d := digitsOf(s)
+	if len(d) != 11 {
+		return errors.New("в СНИЛС не 11 цифр")
+	}
+	if allSame(d[:9]) {
+		return errors.New("СНИЛС из одинаковых цифр")
+	}
+	if d[:9] <= "001001998" {
+		return nil
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(d[i]-'0') * (9 - i)
+	}
+	check := sum % 101
+	if check == 100 {
+		check = 0
+	}
+	if int(d[9]-'0')*10+int(d[10]-'0') != check {
+		return errors.New("неверное контрольное число СНИЛС")
+	}
+	return nil
+}
+
+// validOGRNIP проверяет контрольное число ОГРНИП (15 цифр): остаток от
+// деления первых 14 цифр на 13. ОГРН организации (13 цифр) не относится к
+// ПДн и не проверяется.
+func validOGRNIP(s string) error {
+	d := digitsOf(s)
+	if len(d) != 15 {
+		return errors.New("в ОГРНИП не 15 цифр")
+	}
+	if allSame(d) {
+		return errors.New("ОГРНИП из одинаковых цифр")
+	}
+
+	rem := 0
+	for _, c := range d[:14] {
+		rem = (rem*10 + int(c-'0')) % 13
+	}
+	if byte('0'+rem%10) != d[14] {
+		return errors.New("неверное контрольное число ОГРНИП")
+	}
+	return nil
+}
+
+// validLuhn проверяет номер банковской карты по алгоритму Луна.
+func validLuhn(s string) error {
+	d := digitsOf(s)
+	if len(d) < 12 || len(d) > 19 {
+		return errors.New("в номере карты должно быть от 12 до 19 цифр")
+	}
+	if allSame(d) {
+		return errors.New("номер карты из одинаковых цифр")
+	}
+
+	sum := 0
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if (len(d)-i)%2 == 0 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	if sum%10 != 0 {
+		return errors.New("неверная контрольная сумма номера карты (алгоритм Луна)")
+	}
+	return nil
+}
+
+// validOMS проверяет контрольную цифру единого номера полиса ОМС (16 цифр):
+// цифры на нечетных местах справа (без контрольной) образуют число, которое
+// умножается на 2, к нему слева приписываются цифры на четных местах;
+// контрольная цифра дополняет сумму цифр результата до кратного 10. По
+// сути это алгоритм Луна, поэтому номер полиса проходит и проверку luhn.
+func validOMS(s string) error {
+	d := digitsOf(s)
+	if len(d) != 16 {
+		return errors.New("в номере полиса ОМС не 16 цифр")
+	}
+	if allSame(d) {
+		return errors.New("номер полиса ОМС из одинаковых цифр")
+	}
+
+	var odd, even []byte
+	for i := 14; i >= 0; i-- {
+		if (14-i)%2 == 0 {
+			odd = append(odd, d[i])
+		} else {
+			even = append(even, d[i])
+		}
+	}
+
+	sum := 0
+	for _, c := range even {
+		sum += int(c - '0')
+	}
+	// Сумма цифр удвоенного числа равна сумме цифр удвоенных цифр: перенос
+	// в соседний разряд заменяет 10 на 1 так же, как n/10 + n%10
+	for _, c := range odd {
+		n := int(c-'0') * 2
+		sum += n/10 + n%10
+	}
+	if byte('0'+(10-sum%10)%10) != d[15] {
+		return errors.New("неверная контрольная цифра полиса ОМС")
+	}
+	return nil
+}
+
+// documentLetters — буквы серий водительских удостоверений и номерных
+// знаков: кириллица, совпадающая по начертанию с латиницей.
+const documentLetters = "авекмнорстух"
+
+const cyrillicLetters = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+
+// latinLookalikes заменяет латинские буквы на совпадающие по начертанию
+// кириллические: в номерах их часто набирают латиницей.
+var latinLookalikes = strings.NewReplacer(
+	"a", "а", "b", "в", "e", "е", "k", "к", "m", "м", "h", "н",
+	"o", "о", "p", "р", "c", "с", "t", "т", "y", "у", "x", "х",
+)
+
+// documentNumber оставляет в номере документа буквы (в нижнем регистре,
+// латинские двойники — кириллицей) и цифры.
+func documentNumber(s string) []rune {
+	s = latinLookalikes.Replace(strings.ToLower(s))
+	var r []rune
+	for _, c := range s {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+func isDigits(r []rune) bool {
+	for _, c := range r {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(r) > 0
+}
+
+func isLettersOf(r []rune, letters string) bool {
+	for _, c := range r {
+		if !strings.ContainsRune(letters, c) {
+			return false
+		}
+	}
+	return len(r) > 0
+}
+
+// validDriverLicense проверяет номер водительского удостоверения: код
+// региона (две цифры, не 00), серия из двух цифр или двух букв
+// documentLetters (до 2011 года) и шесть цифр номера.
+func validDriverLicense(s string) error {
+	r := documentNumber(s)
+	if len(r) != 10 || !isDigits(r[:2]) || !isDigits(r[4:]) {
+		return errors.New("номер водительского удостоверения не в формате 99 99 999999 или 99 АА 999999")
+	}
+	if !isDigits(r[2:4]) && !isLettersOf(r[2:4], documentLetters) {
+		return errors.New("недопустимые буквы в серии водительского удостоверения")
+	}
+	if string(r[:2]) == "00" {
+		return errors.New("код региона в серии водительского удостоверения 00")
+	}
+	if allSame(string(r[4:])) {
+		return errors.New("номер водительского удостоверения из одинаковых цифр")
+	}
+	return nil
+}
+
+// validVehiclePlate проверяет регистрационный знак вида А123ВС77 или
+// А123ВС777: буквы из documentLetters, номер не 000, код региона не 00,
+// трехзначные коды начинаются с 1, 2, 7 или 9.
+func validVehiclePlate(s string) error {
+	r := documentNumber(s)
+	if len(r) != 8 && len(r) != 9 ||
+		!isLettersOf(r[:1], documentLetters) || !isDigits(r[1:4]) ||
+		!isLettersOf(r[4:6], documentLetters) || !isDigits(r[6:]) {
+		return errors.New("номер не в формате А123ВС77")
+	}
+	if string(r[1:4]) == "000" {
+		return errors.New("номер 000 не выдается")
+	}
+	region := string(r[6:])
+	if region == "00" || len(region) == 3 && !strings.ContainsRune("1279", r[6]) {
+		return errors.New("недопустимый код региона " + region)
+	}
+	return nil
+}
+
+// validForeignPassport проверяет номер заграничного паспорта: серия из
+// двух цифр (не 00) и семь цифр номера.
+func validForeignPassport(s string) error {
+	d := digitsOf(s)
+	if len(d) != 9 {
+		return errors.New("в номере загранпаспорта не 9 цифр")
+	}
+	if strings.HasPrefix(d, "00") {
+		return errors.New("серия загранпаспорта 00")
+	}
+	if allSame(d[2:]) {
+		return errors.New("номер загранпаспорта из одинаковых цифр")
+	}
+	return nil
+}
+
+// validMilitaryID проверяет военный билет: серия из двух букв и семь цифр
+// номера.
+func validMilitaryID(s string) error {
+	r := documentNumber(s)
+	if len(r) != 9 || !isLettersOf(r[:2], cyrillicLetters) || !isDigits(r[2:]) {
+		return errors.New("номер военного билета не в формате АА 1234567")
+	}
+	if allSame(string(r[2:])) {
+		return errors.New("номер военного билета из одинаковых цифр")
+	}
+	return nil
+}
+
+// validBirthCertificate проверяет свидетельство о рождении вида
+// IV-МЮ 123456: римское число (код региона), две буквы и шесть цифр.
+func validBirthCertificate(s string) error {
+	roman, rest, ok := strings.Cut(strings.ToLower(s), "-")
+	if !ok {
+		return errors.New("серия свидетельства о рождении не в формате IV-АА")
+	}
+	// Римские цифры набирают и кириллицей: Х и С
+	roman = strings.TrimFunc(roman, func(r rune) bool { return !unicode.IsLetter(r) })
+	roman = strings.NewReplacer("х", "x", "с", "c").Replace(roman)
+	if n := parseRoman(roman); n < 1 || n > 99 {
+		return errors.New("недопустимое римское число в серии свидетельства о рождении")
+	}
+
+	var r []rune
+	for _, c := range rest {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			r = append(r, c)
+		}
+	}
+	if len(r) != 8 || !isLettersOf(r[:2], cyrillicLetters) || !isDigits(r[2:]) {
+		return errors.New("свидетельство о рождении не в формате IV-АА 123456")
+	}
+	if allSame(string(r[2:])) {
+		return errors.New("номер свидетельства о рождении из одинаковых цифр")
+	}
+	return nil
+}
+
+// parseRoman переводит римское число в нижнем регистре; для записей, не
+// являющихся каноническим римским числом (iiii, vx), возвращает 0.
+func parseRoman(s string) int {
+	values := map[byte]int{'i': 1, 'v': 5, 'x': 10, 'l': 50, 'c': 100}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		v, ok := values[s[i]]
+		if !ok {
+			return 0
+		}
+		if i+1 < len(s) && values[s[i+1]] > v {
+			n -= v
+		} else {
+			n += v
+		}
+	}
+	if n <= 0 || n >= 400 || formatRoman(n) != s {
+		return 0
+	}
+	return n
+}
+
+func formatRoman(n int) string {
+	var b strings.Builder
+	for _, p := range []struct {
+		value  int
+		symbol string
+	}{{100, "c"}, {90, "xc"}, {50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+		for ; n >= p.value; n -= p.value {
+			b.WriteString(p.symbol)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import "testing"
+
+// validatorCase — значение и ожидаемый результат проверки.
+type validatorCase struct {
+	value string
+	valid bool
+}
+
+func testValidator(t *testing.T, check func(string) error, cases []validatorCase) {
+	t.Helper()
+	for _, tc := range cases {
+		err := check(tc.value)
+		if tc.valid && err != nil {
+			t.Errorf("%q: %v, ожидалось корректное значение", tc.value, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: ожидалась ошибка проверки", tc.value)
+		}
+	}
+}
+
+func TestValidINNPerson(t *testing.T) {
+	testValidator(t, validINNPerson, []validatorCase{
+		{"500100732259", true},
+		{"5001 0073 2259", true},
+		{"500100732258", false},
+		{"500100732269", false},
+		{"111111111111", false},
+		{"50010073225", false},
+		{"5001007322590", false},
+		// ИНН организации — не ПДн
+		{"7707083893", false},
+	})
+}
+
+func TestValidSNILS(t *testing.T) {
+	testValidator(t, validSNILS, []validatorCase{
+		{"112-233-445 95", true},
+		{"11223344595", true},
+		{"112-233-445 96", false},
+		{"111-111-111 11", false},
+		{"112-233-445 9", false},
+		{"112-233-445 950", false},
+	})
+}
+
+func TestValidOGRNIP(t *testing.T) {
+	testValidator(t, validOGRNIP, []validatorCase{
+		{"304500116000157", true},
+		{"304500116000158", false},
+		{"111111111111111", false},
+		{"30450011600015", false},
+		// ОГРН организации — не ПДн
+		{"1027700132195", false},
+	})
+}
+
+func TestValidLuhn(t *testing.T) {
+	testValidator(t, validLuhn, []validatorCase{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"4111111111111112", false},
+		{"0000000000000000", false},
+		{"41111111111", false},
+		{"41111111111111111111", false},
+	})
+}
+
+func TestValidOMS(t *testing.T) {
+	testValidator(t, validOMS, []validatorCase{
+		{"7700001234567895", true},
+		{"7700 0012 3456 7895", true},
+		{"7700001234567894", false},
+		{"5555555555555555", false},
+		{"770000123456789", false},
+	})
+}
+
+func TestValidDriverLicense(t *testing.T) {
+	testValidator(t, validDriverLicense, []validatorCase{
+		{"77 12 345678", true},
+		{"7712345678", true},
+		{"77 АВ 123456", true},
+		{"77 ab 123456", true},
+		{"00 12 345678", false},
+		{"77 ЖЩ 123456", false},
+		{"77 12 111111", false},
+		{"77 12 34567", false},
+	})
+}
+
+func TestValidVehiclePlate(t *testing.T) {
+	testValidator(t, validVehiclePlate, []validatorCase{
+		{"А123ВС77", true},
+		{"а123вс777", true},
+		{"a123bc777", true},
+		{"А 123 ВС 199", true},
+		{"А000ВС77", false},
+		{"А123ВС00", false},
+		{"А123ВС577", false},
+		{"Б123ВС77", false},
+		{"А123ВС7", false},
+	})
+}
+
+func TestValidMilitaryID(t *testing.T) {
+	testValidator(t, validMilitaryID, []validatorCase{
+		{"АБ 1234567", true},
+		{"аб1234567", true},
+		{"АБ 1111111", false},
+		{"АБ 123456", false},
+		{"12 1234567", false},
+	})
+}
+
+func TestValidBirthCertificate(t *testing.T) {
+	testValidator(t, validBirthCertificate, []validatorCase{
+		{"IV-МЮ 123456", true},
+		{"iv-мю 123456", true},
+		{"ХII-АГ 654321", true},
+		{"IV МЮ 123456", false},
+		{"IIII-МЮ 123456", false},
+		{"IV-MU 123456", false},
+		{"IV-МЮ 12345", false},
+		{"IV-МЮ 111111", false},
+	})
+}